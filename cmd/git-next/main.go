@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"github.com/VectorSophie/git-next/internal/action"
 	"github.com/VectorSophie/git-next/internal/config"
 	"github.com/VectorSophie/git-next/internal/engine"
+	"github.com/VectorSophie/git-next/internal/hook"
 	"github.com/VectorSophie/git-next/internal/output"
 	"github.com/VectorSophie/git-next/internal/repo"
 )
@@ -26,6 +28,7 @@ func main() {
 		formatCompact  bool
 		showDebug      bool
 		interactiveAction bool
+		allowDangerous bool
 		configPath     string
 	)
 
@@ -37,6 +40,7 @@ func main() {
 	flag.BoolVar(&formatCompact, "compact", false, "Output compact one-line summary")
 	flag.BoolVar(&showDebug, "debug", false, "Show debug information (repo state)")
 	flag.BoolVar(&interactiveAction, "action", false, "Interactive mode to execute suggested actions")
+	flag.BoolVar(&allowDangerous, "i-know-what-im-doing", false, "Allow --action to execute advice from the dangerous band (100-90)")
 	flag.StringVar(&configPath, "config", "", "Path to config file (default: .git-next.yaml or ~/.config/git-next/config.yaml)")
 
 	flag.Usage = func() {
@@ -44,6 +48,19 @@ func main() {
 
 Usage:
   git-next [options]
+  git-next <command>
+
+Commands:
+  resolve           Walk through conflicts of an in-progress merge, rebase or cherry-pick
+  squash            Propose and apply a fixup/squash plan for unpushed commits
+  history           Show which refs contain each recent commit (safe to rewrite or not)
+  rescue            Walk the reflog timeline and return to an earlier state
+  undo-last         Undo the last commit, amend, merge, rebase, pull or conflicted stash pop
+  hook install [hooks...]
+                    Install git hooks (default: pre-commit pre-push post-checkout post-merge)
+  hook uninstall [hooks...]
+                    Remove git-next hooks, restoring any hook they chained
+  hook <name>       Run a hook (called by the installed hook scripts)
 
 Options:
   -v, --version     Show version information
@@ -52,6 +69,8 @@ Options:
   --compact         Output compact one-line summary
   --debug           Show debug information (repo state)
   --action          Interactive mode to execute suggested actions
+  --i-know-what-im-doing
+                    Allow --action to execute dangerous-band advice (100-90)
 
 Examples:
   git-next                    # Show current advice
@@ -59,6 +78,12 @@ Examples:
   git-next --json             # Output as JSON
   git-next --compact          # Show compact summary
   git-next --action           # Interactive mode to execute actions
+  git-next resolve            # Resolve conflicts file by file
+  git-next squash             # Clean up noisy unpushed commits
+  git-next history            # Show who has each commit
+  git-next rescue             # Bring back commits left behind
+  git-next undo-last          # Undo whatever you just did
+  git-next hook install       # Check every commit and push automatically
 
 The tool never lies. It analyzes your repository state and suggests
 the least harmful move based on who has the history.
@@ -92,6 +117,18 @@ the least harmful move based on who has the history.
 		}
 	}
 
+	// Run subcommand if one was given
+	if flag.NArg() > 0 {
+		if err := runSubcommand(flag.Args(), cfg); err != nil {
+			// Hooks already explained why they blocked
+			if !errors.Is(err, hook.ErrBlocked) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Collect repository state
 	state, err := repo.CollectState(cfg)
 	if err != nil {
@@ -112,11 +149,16 @@ the least harmful move based on who has the history.
 		fmt.Printf("  OnDetachedHead: %v\n", state.OnDetachedHead)
 		fmt.Printf("  LastCommitPushed: %v\n", state.LastCommitPushed)
 		fmt.Printf("  CommitCountSincePush: %d\n", state.CommitCountSincePush)
+		fmt.Printf("  UnpublishedCount: %d\n", state.UnpublishedCount)
 		fmt.Printf("  OnProtectedBranch: %v\n", state.OnProtectedBranch)
 		fmt.Printf("  HasMergeCommits: %v\n", state.HasMergeCommits)
 		fmt.Printf("  MergeInProgress: %v\n", state.MergeInProgress)
 		fmt.Printf("  RebaseInProgress: %v\n", state.RebaseInProgress)
 		fmt.Printf("  CherryPickInProgress: %v\n", state.CherryPickInProgress)
+		fmt.Printf("  RevertInProgress: %v\n", state.RevertInProgress)
+		fmt.Printf("  AmInProgress: %v\n", state.AmInProgress)
+		fmt.Printf("  BisectInProgress: %v\n", state.BisectInProgress)
+		fmt.Printf("  SequencerInProgress: %v (%s, %d left)\n", state.SequencerInProgress, state.SequencerOperation, state.SequencerRemaining)
 		fmt.Printf("  NoUpstream: %v\n", state.NoUpstream)
 		fmt.Printf("  MergedBranches: %v\n", state.MergedBranches)
 		fmt.Printf("  GoneBranches: %v\n\n", state.GoneBranches)
@@ -127,7 +169,7 @@ the least harmful move based on who has the history.
 
 	// Interactive action mode
 	if interactiveAction {
-		if err := action.Execute(advice); err != nil {
+		if err := action.Execute(advice, state, allowDangerous); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -163,3 +205,57 @@ the least harmful move based on who has the history.
 		os.Exit(1)
 	}
 }
+
+// runSubcommand dispatches git-next subcommands
+func runSubcommand(args []string, cfg *config.Config) error {
+	switch args[0] {
+	case "resolve":
+		return action.Resolve()
+	case "squash":
+		state, err := repo.CollectState(cfg)
+		if err != nil {
+			return err
+		}
+		return action.Squash(state)
+	case "history":
+		state, err := repo.CollectState(cfg)
+		if err != nil {
+			return err
+		}
+		fmt.Print(output.FormatHistory(state))
+		return nil
+	case "rescue":
+		state, err := repo.CollectState(cfg)
+		if err != nil {
+			return err
+		}
+		// Dropped stashes and deleted branches only fsck finds
+		if err := repo.CollectLostWorkDeep(&state, cfg); err != nil {
+			return err
+		}
+		return action.Rescue(state)
+	case "undo-last":
+		state, err := repo.CollectState(cfg)
+		if err != nil {
+			return err
+		}
+		return action.UndoLast(state)
+	case "hook":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: git-next hook <install|uninstall|name> [args...]")
+		}
+		names := args[2:]
+		if len(names) == 0 {
+			names = hook.DefaultHooks
+		}
+		switch args[1] {
+		case "install":
+			return hook.Install(names, os.Stdout)
+		case "uninstall":
+			return hook.Uninstall(names, os.Stdout)
+		}
+		return hook.Run(args[1], args[2:], os.Stdin, os.Stderr, cfg)
+	}
+
+	return fmt.Errorf("unknown command: %s", args[0])
+}
@@ -0,0 +1,58 @@
+package action
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// amendUnpushed runs the amend arguments on HEAD, or replays every commit
+// since the oldest one and runs exec, which decides per commit whether it
+// needs amending. plan is the publication guard from the rule: its first
+// line starts with "fix: " when nothing in the range is on a remote or a merge.
+func amendUnpushed(plan []string, cmd, oldestHash string, depth int, amend []string, exec, label string, reader *bufio.Reader) error {
+	if !strings.HasPrefix(plan[0], "fix: ") {
+		fmt.Printf("\n%s\n", plan[0])
+		return fmt.Errorf("refusing to rewrite these commits")
+	}
+	for _, note := range plan[1:] {
+		fmt.Printf("\n%s\n", note)
+	}
+
+	args := amend
+	if depth > 1 {
+		base, rangeSpec := oldestHash+"^", oldestHash+"^..HEAD"
+		if !refExists(base) {
+			// The oldest commit is the root commit
+			base, rangeSpec = "--root", "HEAD"
+		}
+		merges, _ := gitOutput("rev-list", "--merges", rangeSpec)
+		if strings.TrimSpace(merges) != "" {
+			return fmt.Errorf("%s contains merge commits, rebase would flatten them", rangeSpec)
+		}
+		args = []string{"rebase", "--exec", exec, base}
+		printCommits("Commits to rewrite", commitList(rangeSpec))
+	}
+
+	proceed, err := confirm(cmd, classifyCommand(cmd), describeImpact(cmd), reader)
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		fmt.Println("Cancelled.")
+		return nil
+	}
+
+	backup, err := backupHead(label)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nBackup saved: %s\n", shortRef(backup))
+	fmt.Printf("To undo: git reset --keep %s\n", shortRef(backup))
+
+	if err := runQuiet("git", args...); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Rewrote %d commit(s)\n", depth)
+	return nil
+}
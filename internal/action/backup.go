@@ -0,0 +1,31 @@
+package action
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// backupRefPrefix is where git-next keeps refs saved before rewriting history.
+// They live outside refs/heads so they don't show up as branches to clean up.
+const backupRefPrefix = "refs/git-next/backup/"
+
+// backupHead saves HEAD under a backup ref and returns the ref name
+func backupHead(label string) (string, error) {
+	branch, err := getCurrentBranch()
+	if err != nil || branch == "HEAD" {
+		branch = "detached"
+	}
+
+	ref := fmt.Sprintf("%s%s/%s-%d", backupRefPrefix, branch, label, time.Now().Unix())
+	if err := runQuiet("git", "update-ref", ref, "HEAD"); err != nil {
+		return "", fmt.Errorf("failed to create backup ref: %w", err)
+	}
+
+	return ref, nil
+}
+
+// shortRef strips the refs/ prefix for display
+func shortRef(ref string) string {
+	return strings.TrimPrefix(ref, "refs/")
+}
@@ -11,8 +11,9 @@ import (
 	"github.com/VectorSophie/git-next/pkg/model"
 )
 
-// Execute runs the interactive action selector
-func Execute(advice []model.Advice) error {
+// Execute runs the interactive action selector.
+// Advice from the dangerous band is refused unless allowDangerous is set.
+func Execute(advice []model.Advice, state model.RepoState, allowDangerous bool) error {
 	// Filter out suppressed advice
 	activeAdvice := []model.Advice{}
 	for _, a := range advice {
@@ -33,6 +34,9 @@ func Execute(advice []model.Advice) error {
 
 	for i, a := range activeAdvice {
 		fmt.Printf("%d. [%s] %s\n", i+1, a.RuleID, a.Description)
+		for _, e := range a.Evidence {
+			fmt.Printf("   • %s\n", e)
+		}
 		fmt.Printf("   Command: %s\n", a.Command)
 		fmt.Printf("   Priority: %d\n\n", a.Priority)
 	}
@@ -64,24 +68,59 @@ func Execute(advice []model.Advice) error {
 
 	selectedAdvice := activeAdvice[selection-1]
 
+	// "Put the keyboard down" means exactly that
+	if selectedAdvice.Priority >= DangerousPriority && !allowDangerous {
+		fmt.Printf("\n[%s] is in the dangerous band (priority %d).\n", selectedAdvice.RuleID, selectedAdvice.Priority)
+		fmt.Println("Refusing to execute. Run the command yourself, or pass --i-know-what-im-doing.")
+		return nil
+	}
+
+	// Warnings are advice, not commands
+	if strings.HasPrefix(strings.TrimSpace(selectedAdvice.Command), "#") {
+		fmt.Printf("\n%s\n", selectedAdvice.Command)
+		fmt.Println("This is a warning, there is nothing to execute.")
+		return nil
+	}
+
+	// Squash advice gets a generated todo list instead of a bare editor
+	if selectedAdvice.RuleID == "R049" || selectedAdvice.RuleID == "R022" {
+		return squash(state, reader)
+	}
+
+	// Lost commits are picked and inspected in the rescue browser
+	if selectedAdvice.RuleID == "R066" {
+		return rescueLost(&state, reader)
+	}
+
+	// Lock files are checked again right before removal
+	if selectedAdvice.RuleID == "R070" {
+		return removeStaleLocks(state, reader)
+	}
+
+	// Identity fixes may need a new email before commits are rewritten
+	if selectedAdvice.RuleID == "R073" {
+		return fixIdentity(state, reader)
+	}
+
+	// Signing needs a working setup before commits are re-signed
+	if selectedAdvice.RuleID == "R074" {
+		return fixSigning(state, reader)
+	}
+
 	// Prepare command
 	cmd := selectedAdvice.Command
-	cmd, err = resolveCommand(cmd, reader)
+	cmd, err = resolveCommand(cmd, selectedAdvice.RuleID, newResolver(reader, state))
 	if err != nil {
 		return err
 	}
 
-	// Confirm execution
-	fmt.Printf("\nAbout to execute: %s\n", cmd)
-	fmt.Print("Proceed? (y/N): ")
-
-	confirm, err := reader.ReadString('\n')
+	// Confirm execution, with a typed confirmation for high-risk commands
+	risk := classifyCommand(cmd)
+	proceed, err := confirm(cmd, risk, describeImpact(cmd), reader)
 	if err != nil {
-		return fmt.Errorf("failed to read confirmation: %w", err)
+		return err
 	}
-
-	confirm = strings.TrimSpace(strings.ToLower(confirm))
-	if confirm != "y" && confirm != "yes" {
+	if !proceed {
 		fmt.Println("Cancelled.")
 		return nil
 	}
@@ -91,20 +130,17 @@ func Execute(advice []model.Advice) error {
 }
 
 // resolveCommand handles placeholders in commands
-func resolveCommand(cmd string, reader *bufio.Reader) (string, error) {
+func resolveCommand(cmd string, ruleID string, res *resolver) (string, error) {
+	reader := res.reader
+
 	// Handle <branch> placeholder
 	if strings.Contains(cmd, "<branch>") {
 		// Check if this is a branch cleanup command
-		if strings.Contains(cmd, "git branch -d") {
-			// For branch cleanup, we need to prompt for which branch(es)
-			fmt.Print("\nEnter branch name(s) to delete (space-separated): ")
-			branches, err := reader.ReadString('\n')
+		if strings.Contains(cmd, "git branch -d") || strings.Contains(cmd, "git branch -D") {
+			// For branch cleanup, propose the branches the rule was raised for
+			branches, err := res.resolveBranches(ruleID)
 			if err != nil {
-				return "", fmt.Errorf("failed to read branch names: %w", err)
-			}
-			branches = strings.TrimSpace(branches)
-			if branches == "" {
-				return "", fmt.Errorf("no branches specified")
+				return "", err
 			}
 			cmd = strings.ReplaceAll(cmd, "<branch>", branches)
 		} else {
@@ -119,28 +155,51 @@ func resolveCommand(cmd string, reader *bufio.Reader) (string, error) {
 
 	// Handle <files> placeholder
 	if strings.Contains(cmd, "<files>") {
-		fmt.Print("\nEnter file pattern (e.g., '.' for all, or specific files): ")
-		files, err := reader.ReadString('\n')
+		files, err := res.resolveFiles(ruleID)
 		if err != nil {
-			return "", fmt.Errorf("failed to read files: %w", err)
-		}
-		files = strings.TrimSpace(files)
-		if files == "" {
-			files = "."
+			return "", err
 		}
 		cmd = strings.ReplaceAll(cmd, "<files>", files)
 	}
 
+	// Handle <base> placeholder
+	if strings.Contains(cmd, "<base>") {
+		if res.state.AutosquashBase == "" {
+			return "", fmt.Errorf("no autosquash base found")
+		}
+		cmd = strings.ReplaceAll(cmd, "<base>", res.state.AutosquashBase)
+	}
+
+	// Handle <fork-point> placeholder
+	if strings.Contains(cmd, "<fork-point>") {
+		if res.state.UpstreamForkPoint == "" {
+			return "", fmt.Errorf("no fork point found")
+		}
+		cmd = strings.ReplaceAll(cmd, "<fork-point>", res.state.UpstreamForkPoint)
+	}
+
+	// Handle <pattern> placeholder
+	if strings.Contains(cmd, "<pattern>") {
+		if len(res.state.LFSTrackPatterns) == 0 {
+			return "", fmt.Errorf("no LFS track pattern found")
+		}
+		cmd = strings.ReplaceAll(cmd, "<pattern>", strings.Join(res.state.LFSTrackPatterns, " "))
+	}
+
+	// Handle <suggested-name> placeholder
+	if strings.Contains(cmd, "<suggested-name>") {
+		rename, err := res.resolveBranchRename()
+		if err != nil {
+			return "", err
+		}
+		cmd = strings.ReplaceAll(cmd, "<suggested-name>", rename)
+	}
+
 	// Handle HEAD~N placeholder
 	if strings.Contains(cmd, "HEAD~N") {
-		fmt.Print("\nEnter number of commits: ")
-		num, err := reader.ReadString('\n')
+		num, err := res.resolveCommitCount()
 		if err != nil {
-			return "", fmt.Errorf("failed to read commit count: %w", err)
-		}
-		num = strings.TrimSpace(num)
-		if num == "" {
-			num = "1"
+			return "", err
 		}
 		cmd = strings.ReplaceAll(cmd, "HEAD~N", "HEAD~"+num)
 	}
@@ -180,6 +239,16 @@ func getCurrentBranch() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// gitOutput runs a git command and returns its stdout
+func gitOutput(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
 // executeGitCommand runs a git command and streams output
 func executeGitCommand(cmdStr string) error {
 	fmt.Println("\n───────────────────────────────")
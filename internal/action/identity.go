@@ -0,0 +1,54 @@
+package action
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/config"
+	"github.com/VectorSophie/git-next/internal/rules"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// fixIdentity sets user.email when it is the problem, then amends the
+// flagged unpushed commits with it (R073)
+func fixIdentity(state model.RepoState, reader *bufio.Reader) error {
+	if state.IdentityProblem != "" {
+		if state.IdentityEmail != "" {
+			fmt.Printf("\n%s (commits get %s).\n", state.IdentityProblem, state.IdentityEmail)
+		} else {
+			fmt.Printf("\n%s.\n", state.IdentityProblem)
+		}
+		if len(state.AllowedEmails) > 0 {
+			fmt.Printf("Allowed for %s: %s\n", state.IdentityRemote, strings.Join(state.AllowedEmails, ", "))
+		}
+		fmt.Print("Email for this repository: ")
+
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read email: %w", err)
+		}
+		email := strings.TrimSpace(input)
+		if email == "" {
+			// Resetting the author would only repeat the wrong address
+			fmt.Println("Cancelled.")
+			return nil
+		}
+		if len(state.AllowedEmails) > 0 && !config.IsEmailAllowed(email, state.AllowedEmails) {
+			return fmt.Errorf("%s is not allowed for %s", email, state.IdentityRemote)
+		}
+
+		if err := runQuiet("git", "config", "user.email", email); err != nil {
+			return err
+		}
+		fmt.Printf("✓ user.email is %s for this repository\n", email)
+	}
+
+	if len(state.IdentityCommits) == 0 {
+		return nil
+	}
+
+	oldest := state.IdentityCommits[len(state.IdentityCommits)-1]
+	return amendUnpushed(rules.IdentityRewritePlan(state), rules.IdentityRewriteCommand(state.IdentityCommits),
+		oldest.Hash, oldest.Depth, rules.IdentityAmend(oldest), rules.IdentityExec(state.IdentityCommits), "identity", reader)
+}
@@ -0,0 +1,78 @@
+package action
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/repo"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// removeStaleLocks deletes the lock files R070 found, after checking again
+// that no git process has started in the meantime
+func removeStaleLocks(state model.RepoState, reader *bufio.Reader) error {
+	gitDir, err := gitOutput("rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return fmt.Errorf("not a git repository")
+	}
+
+	running, known := repo.GitProcessRunning(strings.TrimSpace(gitDir))
+	if running {
+		return fmt.Errorf("a git process is running in this repository now, let it finish first")
+	}
+
+	var locks []model.LockFile
+	var imp impact
+	for _, l := range state.StaleLocks {
+		info, err := os.Stat(l.Path)
+		// Gone, or taken again by a new process since we looked
+		if err != nil || info.ModTime().Unix() != l.Timestamp {
+			continue
+		}
+		locks = append(locks, l)
+		if strings.HasPrefix(l.Name, "refs/") {
+			imp.Refs = append(imp.Refs, strings.TrimSuffix(l.Name, ".lock"))
+		}
+	}
+	if len(locks) == 0 {
+		fmt.Println("✓ The lock files are gone already.")
+		return nil
+	}
+
+	fmt.Println("\nLock files:")
+	for _, l := range locks {
+		fmt.Printf("  .git/%s (taken %s)\n", l.Name, l.Age())
+	}
+	if !known {
+		fmt.Println("\nCould not check for running git processes.")
+		fmt.Println("Close editors, IDEs and other terminals using this repository first.")
+	}
+
+	names := make([]string, len(locks))
+	for i, l := range locks {
+		names[i] = shellQuote(l.Path)
+	}
+	cmd := "rm " + strings.Join(names, " ")
+
+	proceed, err := confirm(cmd, RiskLocalDestructive, imp, reader)
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		fmt.Println("Cancelled.")
+		return nil
+	}
+
+	for _, l := range locks {
+		if err := os.Remove(l.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", l.Name, err)
+		}
+		fmt.Printf("✓ Removed .git/%s\n", l.Name)
+	}
+
+	// The crashed command may have done part of its work
+	fmt.Println("\nCheck where it left off with: git status")
+	return nil
+}
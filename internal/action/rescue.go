@@ -0,0 +1,321 @@
+package action
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/repo"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// Rescue browses the reflog timeline of HEAD or the current branch and
+// returns to an earlier state. Lost commits (R066) are one key away.
+func Rescue(state model.RepoState) error {
+	return rescue(state, bufio.NewReader(os.Stdin))
+}
+
+// rescue runs the timeline browser with an existing input reader
+func rescue(state model.RepoState, reader *bufio.Reader) error {
+	branch, err := getCurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	ref := "HEAD"
+	limit := timelinePage
+	for {
+		entries, err := repo.ReadReflog(ref, limit)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("\nGit Next - Rescue")
+		fmt.Println("═══════════════════════════════════")
+		fmt.Printf("Timeline of %s (newest first):\n\n", ref)
+
+		for i, e := range entries {
+			fmt.Printf("%2d. %s %-16s %s\n", i+1, shortHash(e.Hash), model.FormatAge(e.Time), e.Label())
+		}
+
+		options := []string{fmt.Sprintf("Select entry (1-%d)", len(entries)), "'m' for more"}
+		if ref == "HEAD" && branch != "HEAD" {
+			options = append(options, fmt.Sprintf("'b' for the %s reflog", branch))
+		} else if ref != "HEAD" {
+			options = append(options, "'h' for the HEAD reflog")
+		}
+		if len(state.LostCommits) > 0 {
+			options = append(options, fmt.Sprintf("'l' for %d lost commit(s)", len(state.LostCommits)))
+		}
+		options = append(options, "'q' to quit")
+
+		fmt.Printf("\n%s: ", strings.Join(options, ", "))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		input = strings.TrimSpace(input)
+
+		switch input {
+		case "q", "Q", "":
+			return nil
+		case "m", "M":
+			limit += timelinePage
+			continue
+		case "b", "B":
+			if branch != "HEAD" {
+				ref, limit = branch, timelinePage
+			}
+			continue
+		case "h", "H":
+			ref, limit = "HEAD", timelinePage
+			continue
+		case "l", "L":
+			if err := rescueLost(&state, reader); err != nil {
+				return err
+			}
+			continue
+		}
+
+		selection, err := strconv.Atoi(input)
+		if err != nil || selection < 1 || selection > len(entries) {
+			fmt.Printf("Invalid selection: %s\n", input)
+			continue
+		}
+
+		if err := returnTo(entries[selection-1], reader); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+}
+
+// timelinePage is how many reflog entries the timeline shows at a time
+const timelinePage = 20
+
+// returnTo shows what going back to a reflog entry changes, then resets
+// to it or creates a branch there
+func returnTo(entry repo.ReflogEntry, reader *bufio.Reader) error {
+	head, err := gitOutput("rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	if strings.TrimSpace(head) == entry.Hash {
+		fmt.Println("\n✓ This is where HEAD is now.")
+		return nil
+	}
+
+	target := shortHash(entry.Hash)
+	fmt.Printf("\nGoing back to %s (%s):\n", entry.Selector, entry.Label())
+
+	gained := commitList("HEAD.." + entry.Hash)
+	left := commitList(entry.Hash + "..HEAD")
+	printCommits("Commits you get back", gained)
+	printCommits("Commits you leave behind", left)
+
+	if stat, err := gitOutput("diff", "--stat", "HEAD", entry.Hash); err == nil && strings.TrimSpace(stat) != "" {
+		fmt.Printf("\nChanges compared to HEAD:\n%s", stat)
+	}
+
+	fmt.Printf("\n'r' to reset to %s (git reset --keep), 'b' to create a branch there, Enter to go back: ", target)
+	choice, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read choice: %w", err)
+	}
+
+	switch strings.TrimSpace(strings.ToLower(choice)) {
+	case "r":
+		return resetTo(entry.Hash, reader)
+	case "b":
+		return branchAt(entry.Hash, reader)
+	}
+	return nil
+}
+
+// resetTo moves the current branch back with reset --keep after saving a backup
+func resetTo(hash string, reader *bufio.Reader) error {
+	cmd := "git reset --keep " + hash
+	risk := classifyCommand(cmd)
+
+	// Published commits come back on the next pull, getting rid of them needs a force-push
+	if published := publishedCommits(hash); len(published) > 0 {
+		fmt.Printf("\n%d of the commits you leave behind are already on a remote.\n", len(published))
+		fmt.Println("Pushing afterwards needs --force. Consider git revert instead.")
+		risk = RiskLocalDestructive
+	}
+
+	proceed, err := confirm(cmd, risk, describeImpact(cmd), reader)
+	if err != nil || !proceed {
+		if err == nil {
+			fmt.Println("Cancelled.")
+		}
+		return err
+	}
+
+	backup, err := backupHead("rescue")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nBackup saved: %s\n", shortRef(backup))
+	fmt.Printf("To undo: git reset --keep %s\n", shortRef(backup))
+
+	if err := runQuiet("git", "reset", "--keep", hash); err != nil {
+		return err
+	}
+	fmt.Printf("✓ HEAD is now at %s\n", shortHash(hash))
+	return nil
+}
+
+// branchAt creates a new branch at a reflog entry
+func branchAt(hash string, reader *bufio.Reader) error {
+	name := "rescue/" + shortHash(hash)
+	fmt.Printf("Branch name [%s]: ", name)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read branch name: %w", err)
+	}
+	if input = strings.TrimSpace(input); input != "" {
+		name = input
+	}
+
+	if refExists("refs/heads/" + name) {
+		return fmt.Errorf("branch already exists: %s", name)
+	}
+	if err := runQuiet("git", "branch", name, hash); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Created %s. Switch to it with: git switch %s\n", name, name)
+	return nil
+}
+
+// commitList returns one-line descriptions of the commits in a range
+func commitList(rangeSpec string) []string {
+	output, err := gitOutput("log", "--format=%h %s", rangeSpec)
+	if err != nil || strings.TrimSpace(output) == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(output), "\n")
+}
+
+// printCommits prints a titled commit list, capped to keep the screen readable
+func printCommits(title string, commits []string) {
+	const maxShown = 10
+
+	fmt.Printf("\n%s: %d\n", title, len(commits))
+	for i, c := range commits {
+		if i == maxShown {
+			fmt.Printf("  ... and %d more\n", len(commits)-maxShown)
+			break
+		}
+		fmt.Printf("  %s\n", c)
+	}
+}
+
+// rescueLost lists commits nothing references anymore and restores the
+// selected one as a branch, or as a stash entry for dropped stashes (R066)
+func rescueLost(state *model.RepoState, reader *bufio.Reader) error {
+	if len(state.LostCommits) == 0 {
+		fmt.Println("✓ No lost commits. Every commit is on a branch, tag or stash.")
+		return nil
+	}
+
+	for {
+		fmt.Println("\nGit Next - Rescue")
+		fmt.Println("═══════════════════════════════════")
+		fmt.Println("Commits no branch, tag or stash points at:")
+		fmt.Println()
+
+		for i, l := range state.LostCommits {
+			fmt.Printf("%d. %s %s\n", i+1, shortHash(l.Hash), l.Subject)
+			fmt.Printf("   %s, %d commit(s), %s\n", l.Age(), l.Commits, l.Source)
+		}
+
+		fmt.Printf("\nSelect commit to inspect (1-%d), or 'q' to quit: ", len(state.LostCommits))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		input = strings.TrimSpace(input)
+
+		if input == "q" || input == "Q" || input == "" {
+			return nil
+		}
+
+		selection, err := strconv.Atoi(input)
+		if err != nil || selection < 1 || selection > len(state.LostCommits) {
+			fmt.Printf("Invalid selection: %s\n", input)
+			continue
+		}
+
+		restored, err := restoreLost(state.LostCommits[selection-1], reader)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		if restored {
+			state.LostCommits = append(state.LostCommits[:selection-1], state.LostCommits[selection:]...)
+			if len(state.LostCommits) == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// restoreLost shows a lost commit and offers to bring it back
+func restoreLost(l model.LostCommit, reader *bufio.Reader) (bool, error) {
+	fmt.Println()
+	cmd := exec.Command("git", "--no-pager", "log", "--stat", "--format=%C(yellow)%h%Creset %s%n%an, %ar", "-n", strconv.Itoa(l.Commits), l.Hash)
+	if l.Source == "dropped stash" {
+		cmd = exec.Command("git", "--no-pager", "stash", "show", "--stat", l.Hash)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return false, fmt.Errorf("failed to show %s: %w", shortHash(l.Hash), err)
+	}
+
+	restore := []string{"branch", "rescue/" + shortHash(l.Hash), l.Hash}
+	if l.Source == "dropped stash" {
+		restore = []string{"stash", "store", "-m", l.Subject, l.Hash}
+	}
+
+	fmt.Printf("\nRestore with: %s\n", displayGit(restore))
+	fmt.Print("Proceed? (y/N, or type a branch name): ")
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	answer = strings.TrimSpace(answer)
+
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+	case "", "n", "no":
+		fmt.Println("Cancelled.")
+		return false, nil
+	default:
+		// A branch name: restore as that branch, even for a dropped stash
+		if refExists("refs/heads/" + answer) {
+			return false, fmt.Errorf("branch already exists: %s", answer)
+		}
+		restore = []string{"branch", answer, l.Hash}
+	}
+
+	if err := runQuiet("git", restore...); err != nil {
+		return false, err
+	}
+	fmt.Printf("✓ Restored: %s\n", displayGit(restore))
+	return true, nil
+}
+
+// displayGit formats git arguments as a copy-pasteable command
+func displayGit(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = a
+		if strings.ContainsAny(a, " '\"$") {
+			quoted[i] = shellQuote(a)
+		}
+	}
+	return "git " + strings.Join(quoted, " ")
+}
@@ -0,0 +1,333 @@
+package action
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// unmergedPath is a path git could not merge on its own
+type unmergedPath struct {
+	Path  string
+	Code  string
+	Hunks int // -1 when the markers could not be counted
+}
+
+// conflictTypes maps porcelain status codes to human-readable conflict types
+var conflictTypes = map[string]string{
+	"DD": "both deleted",
+	"AU": "added by us",
+	"UD": "deleted by them",
+	"UA": "added by them",
+	"DU": "deleted by us",
+	"AA": "both added",
+	"UU": "both modified",
+}
+
+// Type returns the conflict type
+func (u unmergedPath) Type() string {
+	if t, ok := conflictTypes[u.Code]; ok {
+		return t
+	}
+	return u.Code
+}
+
+// hasContent reports whether both sides kept the file, so markers apply
+func (u unmergedPath) hasContent() bool {
+	return u.Code == "UU" || u.Code == "AA"
+}
+
+// Resolve runs the conflict-resolution assistant for an in-progress
+// merge, rebase, am, cherry-pick or revert
+func Resolve() error {
+	gitDir, err := gitOutput("rev-parse", "--git-dir")
+	if err != nil {
+		return fmt.Errorf("not a git repository")
+	}
+	gitDir = strings.TrimSpace(gitDir)
+
+	// Porcelain paths are relative to the top level
+	if topLevel, err := gitOutput("rev-parse", "--show-toplevel"); err == nil {
+		if err := os.Chdir(strings.TrimSpace(topLevel)); err != nil {
+			return err
+		}
+	}
+
+	op := activeOperation(gitDir)
+	if op == "" {
+		fmt.Println("✓ No merge, rebase, am, cherry-pick or revert in progress.")
+		return nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		paths, err := listUnmergedPaths()
+		if err != nil {
+			return err
+		}
+		paths = markResolved(paths)
+
+		fmt.Printf("\nGit Next - Resolving %s\n", op)
+		fmt.Println("═══════════════════════════════════")
+		if progress := rebaseProgress(gitDir); progress != "" {
+			fmt.Printf("Progress: %s\n", progress)
+		}
+		fmt.Println()
+
+		if len(paths) == 0 {
+			fmt.Println("✓ All conflicts resolved.")
+			return executeGitCommand(fmt.Sprintf("git %s --continue", op))
+		}
+
+		for i, p := range paths {
+			detail := p.Type()
+			if p.hasContent() && p.Hunks >= 0 {
+				detail = fmt.Sprintf("%s, %d conflict hunk(s)", detail, p.Hunks)
+			} else if p.hasContent() {
+				detail += ", conflict hunks unknown"
+			}
+			fmt.Printf("%d. %s (%s)\n", i+1, p.Path, detail)
+		}
+
+		fmt.Printf("\nSelect file to resolve (1-%d), 'r' to refresh, 'a' to abort the %s, 'q' to quit: ", len(paths), op)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		input = strings.TrimSpace(input)
+
+		switch input {
+		case "q", "Q":
+			fmt.Printf("Left the %s in progress. Run git-next resolve again to continue.\n", op)
+			return nil
+		case "r", "R", "":
+			continue
+		case "a", "A":
+			proceed, err := confirm(fmt.Sprintf("git %s --abort", op), RiskLocalDestructive, impact{}, reader)
+			if err != nil {
+				return err
+			}
+			if proceed {
+				return executeGitCommand(fmt.Sprintf("git %s --abort", op))
+			}
+			continue
+		}
+
+		selection, err := strconv.Atoi(input)
+		if err != nil || selection < 1 || selection > len(paths) {
+			fmt.Printf("Invalid selection: %s\n", input)
+			continue
+		}
+
+		if err := resolvePath(paths[selection-1], reader); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+}
+
+// resolvePath resolves a single unmerged path
+func resolvePath(p unmergedPath, reader *bufio.Reader) error {
+	if !p.hasContent() {
+		// One side deleted the file: the user decides whether it survives
+		fmt.Printf("\n%s was %s. Keep the file or delete it? (k/d): ", p.Path, p.Type())
+		choice, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read choice: %w", err)
+		}
+		switch strings.TrimSpace(strings.ToLower(choice)) {
+		case "k", "keep":
+			return runQuiet("git", "add", "--", p.Path)
+		case "d", "delete":
+			return runQuiet("git", "rm", "--quiet", "--", p.Path)
+		}
+		return nil
+	}
+
+	cmd := exec.Command("git", "mergetool", "--no-prompt", "--", p.Path)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	if err := cmd.Run(); err != nil {
+		// The tool may have been closed without saving, re-check the markers below
+		fmt.Fprintf(os.Stderr, "mergetool exited: %v\n", err)
+	}
+
+	// mergetool stages the file itself when the tool reports success,
+	// otherwise mark it resolved once no markers remain
+	if hunks := countConflictHunks(p.Path); hunks > 0 {
+		fmt.Printf("%s still has %d conflict hunk(s).\n", p.Path, hunks)
+		return nil
+	} else if hunks < 0 {
+		fmt.Printf("Could not check %s for conflict markers, stage it yourself when it is resolved.\n", p.Path)
+		return nil
+	}
+
+	if err := runQuiet("git", "add", "--", p.Path); err != nil {
+		return err
+	}
+	fmt.Printf("✓ %s marked resolved\n", p.Path)
+	return nil
+}
+
+// markResolved stages text files whose conflict markers are all gone
+// and returns the paths that still need attention
+func markResolved(paths []unmergedPath) []unmergedPath {
+	var remaining []unmergedPath
+	for _, p := range paths {
+		// Binary conflicts never have markers, they need an explicit choice
+		if p.hasContent() && p.Hunks == 0 && !isBinaryFile(p.Path) {
+			if err := runQuiet("git", "add", "--", p.Path); err == nil {
+				fmt.Printf("✓ %s has no conflict markers left, marked resolved\n", p.Path)
+				continue
+			}
+		}
+		remaining = append(remaining, p)
+	}
+	return remaining
+}
+
+// activeOperation returns the git command of the operation in progress
+func activeOperation(gitDir string) string {
+	switch {
+	case pathExists(filepath.Join(gitDir, "rebase-apply", "applying")):
+		return "am"
+	case pathExists(filepath.Join(gitDir, "rebase-merge")),
+		pathExists(filepath.Join(gitDir, "rebase-apply")):
+		return "rebase"
+	case pathExists(filepath.Join(gitDir, "MERGE_HEAD")):
+		return "merge"
+	case pathExists(filepath.Join(gitDir, "CHERRY_PICK_HEAD")):
+		return "cherry-pick"
+	case pathExists(filepath.Join(gitDir, "REVERT_HEAD")):
+		return "revert"
+	}
+	return ""
+}
+
+// rebaseProgress returns "step N of M" for a rebase in progress
+func rebaseProgress(gitDir string) string {
+	// Interactive and merge-backend rebases use msgnum/end,
+	// the apply backend uses next/last
+	dirs := []struct{ dir, current, total string }{
+		{"rebase-merge", "msgnum", "end"},
+		{"rebase-apply", "next", "last"},
+	}
+
+	for _, d := range dirs {
+		current, err1 := os.ReadFile(filepath.Join(gitDir, d.dir, d.current))
+		total, err2 := os.ReadFile(filepath.Join(gitDir, d.dir, d.total))
+		if err1 == nil && err2 == nil {
+			return fmt.Sprintf("commit %s of %s", strings.TrimSpace(string(current)), strings.TrimSpace(string(total)))
+		}
+	}
+
+	return ""
+}
+
+// listUnmergedPaths returns unmerged paths with their conflict type
+func listUnmergedPaths() ([]unmergedPath, error) {
+	output, err := gitOutput("status", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status: %w", err)
+	}
+
+	var paths []unmergedPath
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		code := line[:2]
+		if _, ok := conflictTypes[code]; !ok {
+			continue
+		}
+
+		p := unmergedPath{Path: strings.Trim(line[3:], "\""), Code: code}
+		if p.hasContent() {
+			p.Hunks = countConflictHunks(p.Path)
+		}
+		paths = append(paths, p)
+	}
+
+	return paths, nil
+}
+
+// countConflictHunks counts conflict start markers in a working-tree file,
+// honoring its conflict-marker-size attribute. Returns -1 when the file or
+// the attribute can't be read, so nothing is staged on a guess.
+func countConflictHunks(path string) int {
+	// Porcelain paths are relative to the top of the worktree
+	top, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return -1
+	}
+	top = strings.TrimSpace(top)
+
+	content, err := os.ReadFile(filepath.Join(top, path))
+	if err != nil {
+		return -1
+	}
+
+	// Output is "<path>: conflict-marker-size: <value>"
+	attr, err := gitOutput("-C", top, "check-attr", "conflict-marker-size", "--", path)
+	sep := strings.LastIndex(attr, ": ")
+	if err != nil || sep < 0 {
+		return -1
+	}
+	size := 7
+	value := strings.TrimSpace(attr[sep+2:])
+	if value != "unspecified" && value != "unset" && value != "set" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			size = n
+		} else {
+			return -1
+		}
+	}
+
+	marker := strings.Repeat("<", size)
+	hunks := 0
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == marker || strings.HasPrefix(line, marker+" ") {
+			hunks++
+		}
+	}
+	return hunks
+}
+
+// isBinaryFile checks the start of a file for null bytes, like git does
+func isBinaryFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	buf := make([]byte, 8000)
+	n, _ := f.Read(buf)
+	for _, b := range buf[:n] {
+		if b == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// runQuiet runs a command, surfacing stderr only on failure
+func runQuiet(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s %s failed: %s", name, strings.Join(args, " "), strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// pathExists checks if a file or directory exists
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,244 @@
+package action
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/repo"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// resolver proposes placeholder values derived from the collected repo state.
+// Every proposal can be overridden by typing a value manually.
+type resolver struct {
+	reader *bufio.Reader
+	state  model.RepoState
+}
+
+// newResolver creates a resolver for the given state
+func newResolver(reader *bufio.Reader, state model.RepoState) *resolver {
+	return &resolver{reader: reader, state: state}
+}
+
+// branchCandidates returns the branches a cleanup rule was raised for
+func (r *resolver) branchCandidates(ruleID string) []string {
+	switch ruleID {
+	case "R035":
+		return r.state.MergedBranches
+	case "R036":
+		return r.state.GoneBranches
+	case "R061":
+		return r.state.SquashMergedBranches
+	case "R057":
+		return r.state.InactiveBranches
+	}
+	return nil
+}
+
+// fileCandidates returns the paths a staging rule was raised for
+func (r *resolver) fileCandidates(ruleID string) []string {
+	switch ruleID {
+	case "R002":
+		return r.state.ModifiedPaths
+	case "R007":
+		return r.state.UntrackedPaths
+	case "R065":
+		return r.state.DirtyUpstreamOverlap
+	case "R071":
+		var paths []string
+		seen := make(map[string]bool)
+		for _, f := range r.state.SecretFindings {
+			if !f.Committed && !seen[f.Path] {
+				seen[f.Path] = true
+				paths = append(paths, f.Path)
+			}
+		}
+		return paths
+	}
+	return append(append([]string{}, r.state.ModifiedPaths...), r.state.UntrackedPaths...)
+}
+
+// resolveBranches asks which of the candidate branches to use.
+// Manually typed names are accepted but must exist as local branches.
+func (r *resolver) resolveBranches(ruleID string) (string, error) {
+	candidates := r.branchCandidates(ruleID)
+
+	selected, err := r.multiSelect("branches", candidates, nil)
+	if err != nil {
+		return "", err
+	}
+
+	for _, branch := range selected {
+		if !refExists("refs/heads/" + branch) {
+			return "", fmt.Errorf("branch does not exist: %s", branch)
+		}
+	}
+
+	return strings.Join(selected, " "), nil
+}
+
+// resolveFiles asks which of the candidate paths to stage.
+// There is deliberately no default: staging "." is how secrets get committed.
+// For the same reason "a" leaves out files named like secrets (R071), except
+// when R071 itself asks which ones to unstage.
+func (r *resolver) resolveFiles(ruleID string) (string, error) {
+	candidates := r.fileCandidates(ruleID)
+
+	var holdBack func(string) bool
+	if ruleID != "R071" {
+		holdBack = repo.IsSecretFile
+	}
+
+	selected, err := r.multiSelect("files", candidates, holdBack)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(selected, " "), nil
+}
+
+// resolveBranchRename proposes the policy-conforming name for the first
+// branch R072 flagged. Renaming another branch than the current one needs
+// the old name as well.
+func (r *resolver) resolveBranchRename() (string, error) {
+	if len(r.state.BranchNameViolations) == 0 {
+		return "", fmt.Errorf("no branch to rename")
+	}
+	v := r.state.BranchNameViolations[0]
+
+	if v.Suggested != "" {
+		fmt.Printf("\nNew name for %s [%s]: ", v.Branch, v.Suggested)
+	} else {
+		fmt.Printf("\nNew name for %s (allowed: %s): ", v.Branch, strings.Join(r.state.BranchNamePatterns, ", "))
+	}
+
+	input, err := r.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read branch name: %w", err)
+	}
+	name := strings.TrimSpace(input)
+	if name == "" {
+		name = v.Suggested
+	}
+	if name == "" {
+		return "", fmt.Errorf("no branch name specified")
+	}
+	if refExists("refs/heads/" + name) {
+		return "", fmt.Errorf("branch already exists: %s", name)
+	}
+
+	if v.Current {
+		return name, nil
+	}
+	return v.Branch + " " + name, nil
+}
+
+// resolveCommitCount proposes N for HEAD~N from the unpushed commit count
+func (r *resolver) resolveCommitCount() (string, error) {
+	proposed := r.state.CommitCountSincePush
+	if proposed < 1 {
+		proposed = 1
+	}
+
+	fmt.Printf("\n%d unpushed commit(s) on this branch.\n", r.state.CommitCountSincePush)
+	if r.state.NoisyCommitCount > 0 {
+		fmt.Printf("%d of them look like noise.\n", r.state.NoisyCommitCount)
+	}
+	fmt.Printf("Enter number of commits [%d]: ", proposed)
+
+	input, err := r.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit count: %w", err)
+	}
+	input = strings.TrimSpace(input)
+	if input == "" {
+		input = strconv.Itoa(proposed)
+	}
+
+	num, err := strconv.Atoi(input)
+	if err != nil || num < 1 {
+		return "", fmt.Errorf("invalid commit count: %s", input)
+	}
+
+	if !refExists(fmt.Sprintf("HEAD~%d", num)) {
+		return "", fmt.Errorf("HEAD~%d does not exist", num)
+	}
+
+	if r.state.CommitCountSincePush > 0 && num > r.state.CommitCountSincePush {
+		fmt.Printf("Warning: HEAD~%d reaches past the %d unpushed commit(s) into published history.\n",
+			num, r.state.CommitCountSincePush)
+	}
+
+	return strconv.Itoa(num), nil
+}
+
+// multiSelect shows numbered candidates and reads a selection.
+// Input may be numbers ("1 3"), "a" for all candidates, or names typed manually.
+// Candidates holdBack matches are left out of "a" and must be picked explicitly.
+func (r *resolver) multiSelect(kind string, candidates []string, holdBack func(string) bool) ([]string, error) {
+	if len(candidates) > 0 {
+		fmt.Printf("\nCandidate %s:\n", kind)
+		for i, c := range candidates {
+			fmt.Printf("  %d. %s\n", i+1, c)
+		}
+		fmt.Printf("Select %s (e.g. '1 3', 'a' for all) or type names: ", kind)
+	} else {
+		fmt.Printf("\nEnter %s (space-separated): ", kind)
+	}
+
+	input, err := r.reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", kind, err)
+	}
+
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no %s specified", kind)
+	}
+
+	if len(fields) == 1 && (fields[0] == "a" || fields[0] == "A") && len(candidates) > 0 {
+		if holdBack == nil {
+			return candidates, nil
+		}
+		var all, held []string
+		for _, c := range candidates {
+			if holdBack(c) {
+				held = append(held, c)
+			} else {
+				all = append(all, c)
+			}
+		}
+		if len(held) > 0 {
+			fmt.Printf("Left out, they look like secrets: %s (pick them by number to include them)\n", strings.Join(held, ", "))
+		}
+		if len(all) == 0 {
+			return nil, fmt.Errorf("no %s selected", kind)
+		}
+		return all, nil
+	}
+
+	// All numeric: pick from the candidate list
+	var picked []string
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			// Not a selection, treat the whole input as manual entry
+			return fields, nil
+		}
+		if n < 1 || n > len(candidates) {
+			return nil, fmt.Errorf("invalid selection: %s", f)
+		}
+		picked = append(picked, candidates[n-1])
+	}
+
+	return picked, nil
+}
+
+// refExists checks whether a revision resolves to an object
+func refExists(rev string) bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", rev)
+	return cmd.Run() == nil
+}
@@ -0,0 +1,254 @@
+package action
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// Risk classifies what a command can do to the repository
+type Risk int
+
+const (
+	// RiskReadOnly commands only inspect state
+	RiskReadOnly Risk = iota
+	// RiskLocalReversible commands change local state that the reflog can restore
+	RiskLocalReversible
+	// RiskLocalDestructive commands discard local work or refs
+	RiskLocalDestructive
+	// RiskRemoteAffecting commands change what other people see
+	RiskRemoteAffecting
+)
+
+// String returns a human-readable risk label
+func (r Risk) String() string {
+	switch r {
+	case RiskReadOnly:
+		return "read-only"
+	case RiskLocalReversible:
+		return "local, reversible"
+	case RiskLocalDestructive:
+		return "local, destructive"
+	case RiskRemoteAffecting:
+		return "affects remote"
+	}
+	return "unknown"
+}
+
+// HighRisk reports whether the risk requires a typed confirmation
+func (r Risk) HighRisk() bool {
+	return r >= RiskLocalDestructive
+}
+
+// DangerousPriority is the lowest priority of the "Put the keyboard down" band
+const DangerousPriority = 90
+
+// classifyCommand returns the highest risk of all parts of a compound command
+func classifyCommand(cmdStr string) Risk {
+	highest := RiskReadOnly
+	for _, part := range strings.Split(cmdStr, "&&") {
+		if r := classifySingle(strings.TrimSpace(part)); r > highest {
+			highest = r
+		}
+	}
+	return highest
+}
+
+// classifySingle classifies a single git command
+func classifySingle(cmdStr string) Risk {
+	parts := strings.Fields(cmdStr)
+	if len(parts) < 2 || parts[0] != "git" {
+		return RiskReadOnly
+	}
+
+	sub := parts[1]
+	args := parts[2:]
+
+	switch sub {
+	case "status", "log", "diff", "show", "reflog", "ls-files":
+		return RiskReadOnly
+	case "push":
+		return RiskRemoteAffecting
+	case "reset":
+		if hasArg(args, "--soft") || hasArg(args, "--keep") {
+			return RiskLocalReversible
+		}
+		return RiskLocalDestructive
+	case "branch":
+		if hasArg(args, "-d") || hasArg(args, "-D") || hasArg(args, "--delete") {
+			return RiskLocalDestructive
+		}
+		return RiskLocalReversible
+	case "stash":
+		if hasArg(args, "clear") || hasArg(args, "drop") {
+			return RiskLocalDestructive
+		}
+		return RiskLocalReversible
+	case "clean", "gc":
+		return RiskLocalDestructive
+	case "merge", "rebase", "cherry-pick", "revert":
+		// Aborting throws away conflict resolution work
+		if hasArg(args, "--abort") {
+			return RiskLocalDestructive
+		}
+		return RiskLocalReversible
+	case "checkout":
+		if hasArg(args, "--") || hasArg(args, "-f") || hasArg(args, "--force") {
+			return RiskLocalDestructive
+		}
+		return RiskLocalReversible
+	}
+
+	return RiskLocalReversible
+}
+
+// hasArg checks if an argument is present
+func hasArg(args []string, want string) bool {
+	for _, a := range args {
+		if a == want {
+			return true
+		}
+	}
+	return false
+}
+
+// impact describes the refs and commits a command will touch
+type impact struct {
+	Refs  []string
+	Range string
+}
+
+// describeImpact works out which refs and commit range a command affects
+func describeImpact(cmdStr string) impact {
+	var imp impact
+
+	for _, part := range strings.Split(cmdStr, "&&") {
+		parts := strings.Fields(strings.TrimSpace(part))
+		if len(parts) < 2 || parts[0] != "git" {
+			continue
+		}
+		args := parts[2:]
+
+		switch parts[1] {
+		case "push":
+			branch, _ := getCurrentBranch()
+			upstream, err := gitOutput("rev-parse", "--abbrev-ref", "@{u}")
+			if err == nil {
+				imp.Refs = append(imp.Refs, fmt.Sprintf("%s -> %s", branch, upstream))
+				imp.Range = "@{u}..HEAD"
+			} else {
+				imp.Refs = append(imp.Refs, branch)
+				imp.Range = "HEAD --not --remotes"
+			}
+			if hasArg(args, "--tags") {
+				imp.Refs = append(imp.Refs, "all local tags")
+			}
+		case "reset", "rebase":
+			target := lastRevision(args)
+			if target != "" {
+				branch, _ := getCurrentBranch()
+				imp.Refs = append(imp.Refs, branch)
+				imp.Range = target + "..HEAD"
+			}
+		case "pull", "merge":
+			if hasArg(args, "--continue") || hasArg(args, "--abort") {
+				continue
+			}
+			target := lastRevision(args)
+			if target == "" {
+				target = "@{u}"
+			}
+			branch, _ := getCurrentBranch()
+			imp.Refs = append(imp.Refs, branch)
+			imp.Range = "HEAD.." + target
+		case "revert":
+			if target := lastRevision(args); target != "" {
+				imp.Range = "-1 " + target
+			}
+		case "branch":
+			if hasArg(args, "-d") || hasArg(args, "-D") || hasArg(args, "--delete") {
+				for _, a := range args {
+					if !strings.HasPrefix(a, "-") {
+						imp.Refs = append(imp.Refs, "refs/heads/"+a)
+					}
+				}
+			}
+		}
+	}
+
+	return imp
+}
+
+// lastRevision returns the last non-flag argument of a command
+func lastRevision(args []string) string {
+	for i := len(args) - 1; i >= 0; i-- {
+		if !strings.HasPrefix(args[i], "-") {
+			return args[i]
+		}
+	}
+	return ""
+}
+
+// showImpact prints the affected refs and a short log preview
+func showImpact(imp impact) {
+	if len(imp.Refs) > 0 {
+		fmt.Println("\nAffected refs:")
+		for _, ref := range imp.Refs {
+			fmt.Printf("  %s\n", ref)
+		}
+	}
+
+	if imp.Range == "" {
+		return
+	}
+
+	args := append([]string{"log", "--oneline", "-n", "10"}, strings.Fields(imp.Range)...)
+	preview, err := gitOutput(args...)
+	if err != nil || strings.TrimSpace(preview) == "" {
+		return
+	}
+
+	fmt.Println("\nAffected commits:")
+	for _, line := range strings.Split(strings.TrimSpace(preview), "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+}
+
+// confirmationToken returns what the user must type to confirm a high-risk command
+func confirmationToken(imp impact) string {
+	for _, ref := range imp.Refs {
+		if strings.HasPrefix(ref, "refs/heads/") {
+			return strings.TrimPrefix(ref, "refs/heads/")
+		}
+	}
+	branch, err := getCurrentBranch()
+	if err != nil {
+		return "HEAD"
+	}
+	return branch
+}
+
+// confirm asks for confirmation appropriate to the risk of the command
+func confirm(cmd string, risk Risk, imp impact, reader *bufio.Reader) (bool, error) {
+	fmt.Printf("\nAbout to execute: %s\n", cmd)
+	fmt.Printf("Risk: %s\n", risk)
+	showImpact(imp)
+
+	if !risk.HighRisk() {
+		fmt.Print("\nProceed? (y/N): ")
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return false, fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		return answer == "y" || answer == "yes", nil
+	}
+
+	token := confirmationToken(imp)
+	fmt.Printf("\nThis cannot be undone easily. Type '%s' to proceed: ", token)
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	return strings.TrimSpace(answer) == token, nil
+}
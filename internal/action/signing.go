@@ -0,0 +1,48 @@
+package action
+
+import (
+	"bufio"
+	"fmt"
+
+	"github.com/VectorSophie/git-next/internal/rules"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// fixSigning re-signs the flagged unpushed commits (R074). A broken
+// signing setup has to be fixed by hand first: git-next can't pick keys.
+func fixSigning(state model.RepoState, reader *bufio.Reader) error {
+	if state.SigningProblem != "" {
+		fmt.Printf("\n%s.\n", state.SigningProblem)
+		if len(state.SigningCommits) > 0 {
+			return fmt.Errorf("fix the signing setup before re-signing")
+		}
+		return nil
+	}
+
+	if state.SigningRequired && !state.SigningAutoSign {
+		cmd := "git config commit.gpgsign true"
+		proceed, err := confirm(cmd, classifyCommand(cmd), describeImpact(cmd), reader)
+		if err != nil {
+			return err
+		}
+		if proceed {
+			if err := runQuiet("git", "config", "commit.gpgsign", "true"); err != nil {
+				return err
+			}
+			fmt.Println("✓ New commits are signed")
+		}
+	}
+
+	if len(state.SigningUnknown) > 0 {
+		fmt.Printf("\n%d commit(s) are signed with a key git can't find. Import the signer's key\n", len(state.SigningUnknown))
+		fmt.Println("(gpg --import, or gpg.ssh.allowedSignersFile for SSH), git-next won't re-sign them.")
+	}
+
+	if len(state.SigningCommits) == 0 {
+		return nil
+	}
+
+	oldest := state.SigningCommits[len(state.SigningCommits)-1]
+	return amendUnpushed(rules.SigningRewritePlan(state), rules.SigningRewriteCommand(oldest),
+		oldest.Hash, oldest.Depth, []string{"commit", "--amend", "--no-edit", "-S"}, rules.SigningExec, "signing", reader)
+}
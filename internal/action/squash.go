@@ -0,0 +1,294 @@
+package action
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/repo"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// todoEntry is one line of a rebase todo list
+type todoEntry struct {
+	Action  string
+	Hash    string
+	Subject string
+	Files   []string
+}
+
+// String formats the entry as a rebase todo line
+func (t todoEntry) String() string {
+	return fmt.Sprintf("%s %s %s", t.Action, t.Hash, t.Subject)
+}
+
+// Squash proposes a rebase todo list for the unpushed commits and runs it
+// non-interactively once approved (R049/R022)
+func Squash(state model.RepoState) error {
+	return squash(state, bufio.NewReader(os.Stdin))
+}
+
+// squash runs the todo generator with an existing input reader
+func squash(state model.RepoState, reader *bufio.Reader) error {
+	base, err := squashBase(state, reader)
+	if err != nil {
+		return err
+	}
+
+	merges, _ := gitOutput("rev-list", "--merges", base+"..HEAD")
+	if strings.TrimSpace(merges) != "" {
+		return fmt.Errorf("range %s..HEAD contains merge commits, rebase would flatten them", shortHash(base))
+	}
+
+	if published := publishedCommits(base); len(published) > 0 {
+		fmt.Printf("\n%d commit(s) in this range are already on a remote:\n", len(published))
+		for _, p := range published {
+			fmt.Printf("  %s\n", p)
+		}
+		return fmt.Errorf("refusing to rewrite published history, use git revert instead")
+	}
+
+	todo, err := planTodo(base)
+	if err != nil {
+		return err
+	}
+	if len(todo) < 2 {
+		fmt.Println("✓ Nothing to squash.")
+		return nil
+	}
+
+	fmt.Println("\nProposed rebase plan (oldest first):")
+	fmt.Println("───────────────────────────────")
+	for _, t := range todo {
+		fmt.Printf("  %s\n", t)
+	}
+	fmt.Println("───────────────────────────────")
+	for _, group := range squashMessages(todo) {
+		fmt.Printf("\nSquashed message:\n")
+		for _, subject := range group {
+			fmt.Printf("  %s\n", subject)
+		}
+	}
+
+	todoFile, err := writeTodo(todo)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(todoFile)
+
+	fmt.Print("\nApply this plan? (y/N, 'e' to edit first): ")
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+
+	// The plan shows the squashed messages, so no editor opens for them
+	editor := "GIT_EDITOR=true"
+	switch strings.TrimSpace(strings.ToLower(answer)) {
+	case "y", "yes":
+	case "e", "edit":
+		if err := editFile(todoFile); err != nil {
+			return err
+		}
+		// An edited plan may reword, keep the editor
+		editor = ""
+	default:
+		fmt.Println("Cancelled.")
+		return nil
+	}
+
+	backup, err := backupHead("squash")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nBackup saved: %s\n", shortRef(backup))
+	fmt.Printf("To undo: git reset --keep %s\n", shortRef(backup))
+
+	cmd := exec.Command("git", "rebase", "-i", base)
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=cp "+shellQuote(todoFile))
+	if editor != "" {
+		cmd.Env = append(cmd.Env, editor)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+
+	fmt.Println("\n───────────────────────────────")
+	fmt.Println("Executing...")
+	fmt.Println()
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("rebase stopped: %w (run git-next resolve, or git rebase --abort)", err)
+	}
+
+	fmt.Println()
+	fmt.Println("✓ History cleaned up")
+	return nil
+}
+
+// squashBase returns the commit the rebase starts from
+func squashBase(state model.RepoState, reader *bufio.Reader) (string, error) {
+	if base, err := gitOutput("merge-base", "HEAD", "@{u}"); err == nil {
+		return strings.TrimSpace(base), nil
+	}
+
+	// No upstream: ask how far back to go
+	num, err := newResolver(reader, state).resolveCommitCount()
+	if err != nil {
+		return "", err
+	}
+
+	base, err := gitOutput("rev-parse", "HEAD~"+num)
+	if err != nil {
+		return "", fmt.Errorf("HEAD~%s does not exist", num)
+	}
+	return strings.TrimSpace(base), nil
+}
+
+// planTodo proposes fixup for noisy commits, squash for consecutive commits
+// touching the same files, and pick for the rest
+func planTodo(base string) ([]todoEntry, error) {
+	output, err := gitOutput("log", "--reverse", "--format=%h%x00%s", base+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	var todo []todoEntry
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.SplitN(line, "\x00", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		entry := todoEntry{Action: "pick", Hash: parts[0], Subject: parts[1]}
+		files, _ := gitOutput("diff-tree", "--no-commit-id", "--name-only", "-r", entry.Hash)
+		entry.Files = strings.Fields(files)
+
+		// The first commit has nothing to fold into
+		if len(todo) > 0 {
+			prev := todo[len(todo)-1]
+			if repo.IsNoisyCommitMessage(entry.Subject) {
+				entry.Action = "fixup"
+			} else if sameFiles(prev.Files, entry.Files) {
+				entry.Action = "squash"
+			}
+		}
+
+		todo = append(todo, entry)
+	}
+
+	return todo, nil
+}
+
+// squashMessages returns the subjects each squash group's message joins,
+// in the order git writes them into the combined message. Fixup messages
+// are dropped, like git does.
+func squashMessages(todo []todoEntry) [][]string {
+	var groups [][]string
+	var current []string
+	for _, t := range todo {
+		switch t.Action {
+		case "pick":
+			if len(current) > 1 {
+				groups = append(groups, current)
+			}
+			current = []string{t.Subject}
+		case "squash":
+			current = append(current, t.Subject)
+		}
+	}
+	if len(current) > 1 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+// sameFiles checks if two commits touch exactly the same files
+func sameFiles(a, b []string) bool {
+	if len(a) == 0 || len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]bool)
+	for _, f := range a {
+		seen[f] = true
+	}
+	for _, f := range b {
+		if !seen[f] {
+			return false
+		}
+	}
+	return true
+}
+
+// publishedCommits lists commits after base that a remote branch already contains
+func publishedCommits(base string) []string {
+	all, err := gitOutput("rev-list", base+"..HEAD")
+	if err != nil {
+		return nil
+	}
+	unpublished, err := gitOutput("rev-list", base+"..HEAD", "--not", "--remotes")
+	if err != nil {
+		return nil
+	}
+
+	local := make(map[string]bool)
+	for _, h := range strings.Fields(unpublished) {
+		local[h] = true
+	}
+
+	var published []string
+	for _, h := range strings.Fields(all) {
+		if !local[h] {
+			published = append(published, shortHash(h))
+		}
+	}
+	return published
+}
+
+// writeTodo writes the todo list to a temporary file
+func writeTodo(todo []todoEntry) (string, error) {
+	f, err := os.CreateTemp("", "git-next-todo-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to write todo: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, t := range todo {
+		fmt.Fprintln(w, t)
+	}
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to write todo: %w", err)
+	}
+
+	return f.Name(), nil
+}
+
+// editFile opens a file in the user's git editor
+func editFile(path string) error {
+	editor, err := gitOutput("var", "GIT_EDITOR")
+	if err != nil {
+		return fmt.Errorf("no editor configured: %w", err)
+	}
+
+	cmd := exec.Command("sh", "-c", strings.TrimSpace(editor)+" "+shellQuote(path))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+// shellQuote quotes a string for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shortHash abbreviates a full commit hash
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,176 @@
+package action
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/repo"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// undoPlan is the reversal of the most recent git operation
+type undoPlan struct {
+	Operation   string // readable description of what is being undone
+	Command     string
+	Target      string // where HEAD goes, empty if HEAD stays
+	FastForward bool   // HEAD moved to a commit that already existed
+	Revert      string // what to do instead once the result is published
+	Note        string
+}
+
+// UndoLast works out the most recent operation from the reflog, ORIG_HEAD
+// and in-progress state, and offers the matching reversal
+func UndoLast(state model.RepoState) error {
+	plan, err := planUndo()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Git Next - Undo Last Operation")
+	fmt.Println("═══════════════════════════════════")
+	fmt.Printf("Last operation: %s\n", plan.Operation)
+
+	// Rewriting a published result means a force-push for everyone
+	if plan.Target != "" && !plan.FastForward && len(commitList(plan.Target+"..HEAD")) > 0 &&
+		state.LastCommitPushed {
+		remotes, _ := gitOutput("for-each-ref", "--contains", "HEAD", "--format=%(refname:short)", "refs/remotes")
+		head, _ := gitOutput("rev-parse", "HEAD")
+		fmt.Printf("\n%s is already on %s.\n", shortHash(strings.TrimSpace(head)), strings.Join(strings.Fields(remotes), ", "))
+		fmt.Println("Undoing it locally would need a force-push to stick.")
+		if plan.Revert != "" {
+			fmt.Printf("Use instead: %s\n", plan.Revert)
+		}
+		return fmt.Errorf("refusing to undo a published operation")
+	}
+
+	if plan.Target != "" {
+		printCommits("Commits you get back", commitList("HEAD.."+plan.Target))
+		printCommits("Commits you undo", commitList(plan.Target+"..HEAD"))
+	}
+	if plan.Note != "" {
+		fmt.Printf("\n%s\n", plan.Note)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	proceed, err := confirm(plan.Command, classifyCommand(plan.Command), describeImpact(plan.Command), reader)
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		fmt.Println("Cancelled.")
+		return nil
+	}
+
+	if plan.Target != "" {
+		backup, err := backupHead("undo")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nBackup saved: %s\n", shortRef(backup))
+		fmt.Printf("To redo: git reset --keep %s\n", shortRef(backup))
+	}
+
+	return executeGitCommand(plan.Command)
+}
+
+// planUndo decides how to reverse the most recent operation
+func planUndo() (undoPlan, error) {
+	gitDir, err := gitOutput("rev-parse", "--git-dir")
+	if err != nil {
+		return undoPlan{}, fmt.Errorf("not a git repository")
+	}
+	gitDir = strings.TrimSpace(gitDir)
+
+	// An operation stopped on conflicts: abort it
+	if op := activeOperation(gitDir); op != "" {
+		return undoPlan{
+			Operation: op + " in progress",
+			Command:   fmt.Sprintf("git %s --abort", op),
+		}, nil
+	}
+
+	// Bisect checks out commits on its own, ending it returns to where it started
+	if pathExists(filepath.Join(gitDir, "BISECT_LOG")) {
+		return undoPlan{
+			Operation: "bisect in progress",
+			Command:   "git bisect reset",
+		}, nil
+	}
+
+	// Unmerged paths without an operation: a stash pop or apply that conflicted
+	if paths, err := listUnmergedPaths(); err == nil && len(paths) > 0 {
+		return undoPlan{
+			Operation: fmt.Sprintf("stash pop/apply with %d conflicted file(s)", len(paths)),
+			Command:   "git reset --merge",
+			Note:      "A pop that conflicts keeps the stash: your changes are still in stash@{0}.",
+		}, nil
+	}
+
+	entries, err := repo.ReadReflog("HEAD", 2)
+	if err != nil || len(entries) == 0 {
+		return undoPlan{}, fmt.Errorf("no reflog entries, nothing to undo")
+	}
+	last := entries[0]
+	plan := undoPlan{Operation: last.Label()}
+
+	if len(entries) < 2 {
+		return undoPlan{}, fmt.Errorf("%s is the first entry in the reflog, nothing to go back to", last.Label())
+	}
+
+	msg := last.Message
+	switch {
+	case strings.HasPrefix(msg, "commit (initial)"):
+		return undoPlan{}, fmt.Errorf("the last operation is the first commit, nothing to go back to")
+
+	case strings.HasPrefix(msg, "commit (amend)"):
+		// The pre-amend commit comes back, the amended changes stay staged
+		plan.Command = "git reset --soft HEAD@{1}"
+		plan.Target = "HEAD@{1}"
+		plan.Revert = "git revert HEAD"
+		plan.Note = "The changes made by the amend stay staged."
+
+	case strings.HasPrefix(msg, "commit (merge)"):
+		plan.Command = "git reset --keep ORIG_HEAD"
+		plan.Target = "ORIG_HEAD"
+		plan.Revert = "git revert -m 1 HEAD"
+
+	case strings.HasPrefix(msg, "commit:"):
+		plan.Command = "git reset --soft HEAD@{1}"
+		plan.Target = "HEAD@{1}"
+		plan.Revert = "git revert HEAD"
+		plan.Note = "The commit's changes stay staged."
+
+	case strings.HasPrefix(msg, "cherry-pick"), strings.HasPrefix(msg, "reset:"):
+		plan.Command = "git reset --keep HEAD@{1}"
+		plan.Target = "HEAD@{1}"
+		plan.Revert = "git revert HEAD"
+
+	case strings.HasPrefix(msg, "merge"), strings.HasPrefix(msg, "pull"), strings.HasPrefix(msg, "rebase"):
+		if strings.HasPrefix(msg, "rebase") && !strings.Contains(msg, "(finish)") {
+			return undoPlan{}, fmt.Errorf("unexpected reflog entry %q, run git-next rescue to pick a point to go back to", msg)
+		}
+		if !refExists("ORIG_HEAD") {
+			return undoPlan{}, fmt.Errorf("ORIG_HEAD is not set, run git-next rescue to pick a point to go back to")
+		}
+		plan.Command = "git reset --keep ORIG_HEAD"
+		plan.Target = "ORIG_HEAD"
+		plan.FastForward = strings.Contains(msg, "Fast-forward")
+		if strings.HasPrefix(msg, "rebase") || strings.Contains(msg, "--rebase") {
+			plan.Revert = "leave the rebased commits, or agree on a force-push with everyone who has them"
+		} else {
+			plan.Revert = "git revert -m 1 HEAD"
+		}
+
+	case strings.HasPrefix(msg, "checkout:"):
+		plan.Command = "git checkout -"
+		plan.Note = "Checkouts don't change any commits, this only switches back."
+
+	default:
+		return undoPlan{}, fmt.Errorf("don't know how to undo %q, run git-next rescue to pick a point to go back to", msg)
+	}
+
+	return plan, nil
+}
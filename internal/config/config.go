@@ -1,10 +1,35 @@
 package config
 
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
 // Config represents the git-next configuration
 type Config struct {
-	ProtectedBranches []string          `yaml:"protected_branches"`
-	Rules             RuleConfig        `yaml:"rules"`
-	Suppression       SuppressionConfig `yaml:"suppression"`
+	ProtectedBranches []string              `yaml:"protected_branches"`
+	Rules             RuleConfig            `yaml:"rules"`
+	Suppression       SuppressionConfig     `yaml:"suppression"`
+	Hooks             map[string]HookConfig `yaml:"hooks"`
+	BranchNaming      BranchNamingConfig    `yaml:"branch_naming"`
+	Identity          []IdentityPolicy      `yaml:"identity"`
+}
+
+// IdentityPolicy restricts commit emails for repositories whose remote URL
+// matches Remote (R073). Both are globs where * matches any text, "/" included.
+type IdentityPolicy struct {
+	Remote string   `yaml:"remote"`
+	Emails []string `yaml:"emails"`
+}
+
+// BranchNamingConfig is the branch naming policy (R072).
+// A branch name must match one of Patterns unless it matches an Exempt glob.
+// Protected branches are always exempt. No patterns means no policy.
+type BranchNamingConfig struct {
+	Patterns []string `yaml:"patterns"`
+	Exempt   []string `yaml:"exempt"`
 }
 
 // RuleConfig contains rule-specific configuration
@@ -18,6 +43,13 @@ type SuppressionConfig struct {
 	Custom map[string][]string `yaml:"custom"`
 }
 
+// HookConfig contains per-hook thresholds.
+// Advice at or above Block priority fails the hook, at or above Warn it is printed.
+type HookConfig struct {
+	Block int `yaml:"block"`
+	Warn  int `yaml:"warn"`
+}
+
 // Defaults returns the default configuration
 func Defaults() *Config {
 	return &Config{
@@ -32,9 +64,31 @@ func Defaults() *Config {
 		Suppression: SuppressionConfig{
 			Custom: make(map[string][]string),
 		},
+		Hooks: map[string]HookConfig{
+			"pre-commit":    {Block: 85, Warn: 60},
+			"pre-push":      {Block: 90, Warn: 90},
+			"post-checkout": {Block: 101, Warn: 30},
+			"post-merge":    {Block: 101, Warn: 30},
+		},
 	}
 }
 
+// dropInvalidPatterns removes branch_naming patterns that don't compile
+// and returns why each one was dropped
+func (c *Config) dropInvalidPatterns() []string {
+	var problems []string
+	valid := c.BranchNaming.Patterns[:0]
+	for _, p := range c.BranchNaming.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			problems = append(problems, fmt.Sprintf("ignoring invalid branch_naming pattern %q: %v", p, err))
+			continue
+		}
+		valid = append(valid, p)
+	}
+	c.BranchNaming.Patterns = valid
+	return problems
+}
+
 // IsRuleDisabled checks if a rule is disabled in the configuration
 func (c *Config) IsRuleDisabled(ruleID string) bool {
 	for _, disabled := range c.Rules.Disabled {
@@ -45,6 +99,82 @@ func (c *Config) IsRuleDisabled(ruleID string) bool {
 	return false
 }
 
+// IsProtectedBranch checks a branch name against the protected branches.
+// Entries may be glob patterns such as "release/*".
+func (c *Config) IsProtectedBranch(branch string) bool {
+	for _, protected := range c.ProtectedBranches {
+		if protected == branch {
+			return true
+		}
+		if matched, err := path.Match(protected, branch); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBranchNameExempt checks a branch against the naming exemptions and protected branches
+func (c *Config) IsBranchNameExempt(branch string) bool {
+	if c.IsProtectedBranch(branch) {
+		return true
+	}
+	for _, exempt := range c.BranchNaming.Exempt {
+		if matched, err := path.Match(exempt, branch); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedEmails returns the email globs of the first identity policy whose
+// remote pattern matches the URL, and false if none applies
+func (c *Config) AllowedEmails(remoteURL string) ([]string, bool) {
+	for _, policy := range c.Identity {
+		if globMatch(policy.Remote, remoteURL) {
+			return policy.Emails, true
+		}
+	}
+	return nil, false
+}
+
+// IsEmailAllowed checks an email against a list of globs, ignoring case
+func IsEmailAllowed(email string, allowed []string) bool {
+	for _, glob := range allowed {
+		if globMatch(strings.ToLower(glob), strings.ToLower(email)) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch matches s against a glob where * matches any text
+func globMatch(glob, s string) bool {
+	parts := strings.Split(glob, "*")
+	if len(parts) == 1 {
+		return glob == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(s, part)
+		if idx < 0 {
+			return false
+		}
+		s = s[idx+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+// HookThresholds returns the thresholds for a hook, falling back to defaults
+func (c *Config) HookThresholds(name string) HookConfig {
+	if hc, ok := c.Hooks[name]; ok {
+		return hc
+	}
+	return Defaults().Hooks[name]
+}
+
 // GetIntParam retrieves an integer parameter for a rule with a default fallback
 func (c *Config) GetIntParam(ruleID, param string, defaultVal int) int {
 	if c.Rules.Parameters[ruleID] != nil {
@@ -72,6 +202,41 @@ func (c *Config) GetStringParam(ruleID, param string, defaultVal string) string
 	return defaultVal
 }
 
+// GetBoolParam retrieves a boolean parameter for a rule with a default fallback
+func (c *Config) GetBoolParam(ruleID, param string, defaultVal bool) bool {
+	if c.Rules.Parameters[ruleID] != nil {
+		if val, ok := c.Rules.Parameters[ruleID][param]; ok {
+			if v, ok := val.(bool); ok {
+				return v
+			}
+		}
+	}
+	return defaultVal
+}
+
+// GetStringListParam retrieves a list of strings for a rule. A single
+// string is accepted as a one-element list.
+func (c *Config) GetStringListParam(ruleID, param string) []string {
+	if c.Rules.Parameters[ruleID] == nil {
+		return nil
+	}
+	switch v := c.Rules.Parameters[ruleID][param].(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		var list []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
 // MergeWithDefaults merges the config with defaults
 func (c *Config) MergeWithDefaults() {
 	defaults := Defaults()
@@ -93,4 +258,19 @@ func (c *Config) MergeWithDefaults() {
 	if c.Suppression.Custom == nil {
 		c.Suppression.Custom = make(map[string][]string)
 	}
+
+	// Fill in hooks and thresholds the config doesn't set
+	if c.Hooks == nil {
+		c.Hooks = make(map[string]HookConfig)
+	}
+	for name, def := range defaults.Hooks {
+		hc := c.Hooks[name]
+		if hc.Block == 0 {
+			hc.Block = def.Block
+		}
+		if hc.Warn == 0 {
+			hc.Warn = def.Warn
+		}
+		c.Hooks[name] = hc
+	}
 }
@@ -52,6 +52,10 @@ func LoadFromFile(path string) (*Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
+	// A bad pattern costs only itself, not the rest of the file
+	for _, problem := range cfg.dropInvalidPatterns() {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", path, problem)
+	}
 
 	return &cfg, nil
 }
@@ -19,6 +19,13 @@ var Suppresses = map[string][]string{
 	"rebase --abort":        {"merge", "rebase", "reset", "commit", "pull", "push", "checkout"},
 	"cherry-pick --continue": {"merge", "rebase", "reset", "commit", "pull", "push", "checkout"},
 	"cherry-pick --abort":    {"merge", "rebase", "reset", "commit", "pull", "push", "checkout"},
+	"revert --continue":      {"merge", "rebase", "reset", "commit", "pull", "push", "checkout"},
+	"revert --abort":         {"merge", "rebase", "reset", "commit", "pull", "push", "checkout"},
+	"am --continue":          {"merge", "rebase", "reset", "commit", "pull", "push", "checkout"},
+	"am --abort":             {"merge", "rebase", "reset", "commit", "pull", "push", "checkout"},
+
+	// Bisect moves HEAD around on purpose
+	"bisect": {"merge", "rebase", "reset", "commit", "pull", "push", "checkout"},
 
 	// Original suppression rules
 	"revert": {"reset"},
@@ -29,17 +36,37 @@ var Suppresses = map[string][]string{
 
 // Evaluate runs all rules against the repo state and returns advice
 func Evaluate(state model.RepoState, cfg *config.Config) []model.Advice {
+	return EvaluateRules(state, cfg, nil)
+}
+
+// EvaluateRules runs only the given rules, nil runs all of them. State
+// collected for a subset leaves the other rules' fields empty, so they must
+// not take part in the evaluation or the suppression.
+func EvaluateRules(state model.RepoState, cfg *config.Config, ruleIDs []string) []model.Advice {
 	allRules := rules.AllRules(cfg)
 	var advice []model.Advice
 
+	var subset map[string]bool
+	if ruleIDs != nil {
+		subset = make(map[string]bool)
+		for _, id := range ruleIDs {
+			subset[id] = true
+		}
+	}
+
 	// Evaluate all rules
 	for _, ruleDef := range allRules {
 		// Skip disabled rules
-		if cfg.IsRuleDisabled(ruleDef.ID) {
+		if cfg.IsRuleDisabled(ruleDef.ID) || (subset != nil && !subset[ruleDef.ID]) {
 			continue
 		}
 
 		if ruleDef.Check(state) {
+			var evidence []string
+			if ruleDef.Evidence != nil {
+				evidence = ruleDef.Evidence(state)
+			}
+
 			advice = append(advice, model.Advice{
 				RuleID:      ruleDef.ID,
 				Command:     ruleDef.Command,
@@ -47,6 +74,7 @@ func Evaluate(state model.RepoState, cfg *config.Config) []model.Advice {
 				Priority:    ruleDef.Priority,
 				Suppressed:  false,
 				Reason:      "",
+				Evidence:    evidence,
 			})
 		}
 	}
@@ -122,18 +150,19 @@ func mergeSuppression(defaults map[string][]string, custom map[string][]string)
 
 // extractCommand extracts the primary git command from a command string
 func extractCommand(cmd string) string {
+	// Handle OR commands (e.g., "git rebase ... OR git merge ...").
+	// The first alternative is the advice, so split these before &&.
+	if strings.Contains(cmd, " OR ") {
+		parts := strings.Split(cmd, " OR ")
+		cmd = strings.TrimSpace(parts[0])
+	}
+
 	// Handle compound commands (e.g., "git add <files> && git commit")
 	if strings.Contains(cmd, "&&") {
 		parts := strings.Split(cmd, "&&")
 		cmd = strings.TrimSpace(parts[len(parts)-1])
 	}
 
-	// Handle OR commands (e.g., "git rebase ... OR git merge ...")
-	if strings.Contains(cmd, " OR ") {
-		parts := strings.Split(cmd, " OR ")
-		cmd = strings.TrimSpace(parts[0])
-	}
-
 	// Extract the git command and key flags
 	// (e.g., "git reset --soft HEAD~N" -> "reset")
 	// (e.g., "git merge --continue" -> "merge --continue")
@@ -0,0 +1,107 @@
+package hook
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/VectorSophie/git-next/internal/config"
+	"github.com/VectorSophie/git-next/internal/engine"
+	"github.com/VectorSophie/git-next/internal/repo"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// hookRules is the rule subset each hook enforces. Hooks only collect the
+// state these rules read, so they stay fast on large repositories.
+var hookRules = map[string][]string{
+	"pre-commit": {"R071", "R042", "R043"},
+	// After switching branches: is the new branch set up right
+	"post-checkout": {"R034", "R040", "R045", "R047", "R062", "R072"},
+	// After a merge or pull: submodules to update, branches to clean up
+	"post-merge": {"R040", "R045", "R035", "R036", "R061", "R062"},
+}
+
+// evaluate collects the state a hook's rules need and returns their active advice
+func evaluate(name string, cfg *config.Config) ([]model.Advice, error) {
+	ids, ok := hookRules[name]
+	if !ok {
+		return nil, fmt.Errorf("no rules for hook %s", name)
+	}
+
+	collect := repo.CollectStateFor
+	if name == "pre-commit" {
+		// Only what this commit adds: commits already made are the CLI's
+		collect = repo.CollectStagedStateFor
+	}
+	state, err := collect(cfg, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []model.Advice
+	for _, a := range engine.EvaluateRules(state, cfg, ids) {
+		if a.Suppressed {
+			continue
+		}
+		active = append(active, a)
+	}
+
+	return active, nil
+}
+
+// preCommit blocks commits that stage conflict markers, large binaries or secrets
+func preCommit(stderr io.Writer, cfg *config.Config) error {
+	advice, err := evaluate("pre-commit", cfg)
+	if err != nil {
+		// Unchecked staged changes may hold a secret: fail closed
+		fmt.Fprintf(stderr, "git-next: pre-commit checks failed: %v\n", err)
+		fmt.Fprintln(stderr, "git-next: commit blocked. Bypass with git commit --no-verify if git-next is at fault.")
+		return ErrBlocked
+	}
+
+	thresholds := cfg.HookThresholds("pre-commit")
+	blocked := false
+	for _, a := range advice {
+		if a.Priority < thresholds.Warn && a.Priority < thresholds.Block {
+			continue
+		}
+		writeAdvice(stderr, a)
+		if a.Priority >= thresholds.Block {
+			blocked = true
+		}
+	}
+
+	if blocked {
+		fmt.Fprintln(stderr, "git-next: commit blocked. Fix the above, or bypass with git commit --no-verify.")
+		return ErrBlocked
+	}
+	return nil
+}
+
+// postUpdate prints the top advice after HEAD moved. Git ignores the exit
+// status of post-checkout and post-merge, so these never block.
+func postUpdate(name string, args []string, stderr io.Writer, cfg *config.Config) error {
+	// post-checkout flag 0 means a file checkout, HEAD didn't move
+	if name == "post-checkout" && len(args) >= 3 && args[2] == "0" {
+		return nil
+	}
+
+	advice, err := evaluate(name, cfg)
+	if err != nil || len(advice) == 0 {
+		return nil
+	}
+
+	// Advice is sorted by priority, the first one is the top
+	if advice[0].Priority >= cfg.HookThresholds(name).Warn {
+		writeAdvice(stderr, advice[0])
+	}
+	return nil
+}
+
+// writeAdvice prints a single piece of advice in the human format
+func writeAdvice(w io.Writer, a model.Advice) {
+	fmt.Fprintf(w, "→ [%s] %s\n", a.RuleID, a.Description)
+	for _, e := range a.Evidence {
+		fmt.Fprintf(w, "  • %s\n", e)
+	}
+	fmt.Fprintf(w, "  Command: %s\n\n", a.Command)
+}
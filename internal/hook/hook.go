@@ -0,0 +1,43 @@
+package hook
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/VectorSophie/git-next/internal/config"
+)
+
+// ErrBlocked is returned when a hook refuses the git operation
+var ErrBlocked = errors.New("blocked by git-next")
+
+// Run executes the named git hook with the arguments git passed to it.
+// Hooks read what git sends on stdin and report to stderr.
+func Run(name string, args []string, stdin io.Reader, stderr io.Writer, cfg *config.Config) error {
+	switch name {
+	case "pre-commit":
+		return preCommit(stderr, cfg)
+	case "pre-push":
+		return prePush(args, stdin, stderr, cfg)
+	case "post-checkout", "post-merge":
+		return postUpdate(name, args, stderr, cfg)
+	}
+
+	return fmt.Errorf("unknown hook: %s", name)
+}
+
+// gitOutput runs a git command and returns its stdout
+func gitOutput(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w: %s", err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
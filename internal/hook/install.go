@@ -0,0 +1,163 @@
+package hook
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultHooks are the hooks git-next installs when none are named
+var DefaultHooks = []string{"pre-commit", "pre-push", "post-checkout", "post-merge"}
+
+// shimMarker identifies hook scripts written by git-next
+const shimMarker = "# git-next hook shim"
+
+// chainedSuffix is appended to a hook that existed before git-next was installed
+const chainedSuffix = ".git-next-chained"
+
+// shimTemplate calls any chained hook first, then git-next. Hooks that read
+// stdin (pre-push) get the same input replayed to both.
+const shimTemplate = `#!/bin/sh
+%s
+# Installed by git-next hook install. Remove with git-next hook uninstall.
+GIT_NEXT=$(command -v git-next || echo %s)
+chained="$(dirname "$0")/%s%s"
+%s`
+
+const shimBody = `if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+exec "$GIT_NEXT" hook %s "$@"
+`
+
+const shimBodyStdin = `input=$(cat)
+if [ -x "$chained" ]; then
+	printf '%%s\n' "$input" | "$chained" "$@" || exit $?
+fi
+printf '%%s\n' "$input" | exec "$GIT_NEXT" hook %s "$@"
+`
+
+// Install writes git-next shims into the hooks directory, keeping any
+// existing hook as a chained script
+func Install(names []string, stdout io.Writer) error {
+	dir, err := hooksDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		binary = "git-next"
+	}
+
+	for _, name := range names {
+		if !isSupported(name) {
+			return fmt.Errorf("unsupported hook: %s", name)
+		}
+
+		path := filepath.Join(dir, name)
+		if isShim(path) {
+			fmt.Fprintf(stdout, "✓ %s already installed\n", name)
+			continue
+		}
+
+		if _, err := os.Stat(path); err == nil {
+			if err := os.Rename(path, path+chainedSuffix); err != nil {
+				return fmt.Errorf("failed to preserve existing %s hook: %w", name, err)
+			}
+			fmt.Fprintf(stdout, "  existing %s hook kept as %s%s\n", name, name, chainedSuffix)
+		}
+
+		body := shimBody
+		if name == "pre-push" {
+			body = shimBodyStdin
+		}
+		script := fmt.Sprintf(shimTemplate, shimMarker, shellQuote(binary), name, chainedSuffix, fmt.Sprintf(body, name))
+
+		if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+			return fmt.Errorf("failed to write %s hook: %w", name, err)
+		}
+		fmt.Fprintf(stdout, "✓ installed %s\n", name)
+	}
+
+	fmt.Fprintf(stdout, "\nHooks directory: %s\n", dir)
+	return nil
+}
+
+// Uninstall removes git-next shims and restores chained hooks.
+// Hooks not written by git-next are left alone.
+func Uninstall(names []string, stdout io.Writer) error {
+	dir, err := hooksDir()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if !isShim(path) {
+			fmt.Fprintf(stdout, "  %s not installed by git-next, skipped\n", name)
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove %s hook: %w", name, err)
+		}
+
+		if _, err := os.Stat(path + chainedSuffix); err == nil {
+			if err := os.Rename(path+chainedSuffix, path); err != nil {
+				return fmt.Errorf("failed to restore original %s hook: %w", name, err)
+			}
+			fmt.Fprintf(stdout, "✓ removed %s, original hook restored\n", name)
+			continue
+		}
+		fmt.Fprintf(stdout, "✓ removed %s\n", name)
+	}
+
+	return nil
+}
+
+// hooksDir returns the hooks directory, honoring core.hooksPath
+func hooksDir() (string, error) {
+	output, err := gitOutput("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("not a git repository")
+	}
+
+	dir := strings.TrimSpace(output)
+	if !filepath.IsAbs(dir) {
+		// --git-path is relative to the current directory
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+	}
+	return dir, nil
+}
+
+// isShim checks if a hook file was written by git-next
+func isShim(path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(content), shimMarker)
+}
+
+// isSupported checks if git-next has a handler for a hook
+func isSupported(name string) bool {
+	for _, h := range DefaultHooks {
+		if h == name {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote quotes a string for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
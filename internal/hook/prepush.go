@@ -0,0 +1,167 @@
+package hook
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/config"
+)
+
+// refUpdate is one line of pre-push input:
+// <local ref> <local sha> <remote ref> <remote sha>
+type refUpdate struct {
+	LocalRef  string
+	LocalSHA  string
+	RemoteRef string
+	RemoteSHA string
+}
+
+// isZero checks for the all-zero object name git sends for a ref that
+// does not exist on one side (SHA-1 or SHA-256)
+func isZero(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
+
+// dangerousPriority is the priority of R037/R038
+const dangerousPriority = 100
+
+// violation is a push that the dangerous band forbids. RuleID is empty
+// when the push can't be checked rather than known to be wrong.
+type violation struct {
+	RuleID  string
+	Ref     string
+	Message string
+	Advice  string
+}
+
+// prePush checks every ref update git is about to push (R037/R038)
+func prePush(args []string, stdin io.Reader, stderr io.Writer, cfg *config.Config) error {
+	remote := "remote"
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	updates, err := parseRefUpdates(stdin)
+	if err != nil {
+		return err
+	}
+
+	var violations []violation
+	for _, u := range updates {
+		if v, ok := checkUpdate(u, cfg); ok {
+			violations = append(violations, v)
+		}
+	}
+
+	// Rewriting shared refs is dangerous-band advice (priority 100)
+	thresholds := cfg.HookThresholds("pre-push")
+	if len(violations) == 0 || dangerousPriority < thresholds.Warn && dangerousPriority < thresholds.Block {
+		return nil
+	}
+	blocked := dangerousPriority >= thresholds.Block
+
+	if blocked {
+		fmt.Fprintf(stderr, "git-next: refusing to push to %s\n\n", remote)
+	} else {
+		fmt.Fprintf(stderr, "git-next: warning, pushing to %s\n\n", remote)
+	}
+	for _, v := range violations {
+		if v.RuleID == "" {
+			fmt.Fprintf(stderr, "→ %s: %s\n", v.Ref, v.Message)
+		} else {
+			fmt.Fprintf(stderr, "→ [%s] %s: %s\n", v.RuleID, v.Ref, v.Message)
+		}
+		fmt.Fprintf(stderr, "  %s\n\n", v.Advice)
+	}
+
+	if !blocked {
+		return nil
+	}
+	fmt.Fprintln(stderr, "If this is really intended, bypass the hook with git push --no-verify.")
+	return ErrBlocked
+}
+
+// parseRefUpdates reads the pre-push lines from stdin
+func parseRefUpdates(stdin io.Reader) ([]refUpdate, error) {
+	var updates []refUpdate
+
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 {
+			continue
+		}
+		updates = append(updates, refUpdate{
+			LocalRef:  fields[0],
+			LocalSHA:  fields[1],
+			RemoteRef: fields[2],
+			RemoteSHA: fields[3],
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pre-push input: %w", err)
+	}
+
+	return updates, nil
+}
+
+// checkUpdate decides whether a single ref update is a forbidden rewrite
+func checkUpdate(u refUpdate, cfg *config.Config) (violation, bool) {
+	// New ref on the remote: nothing to rewrite
+	if isZero(u.RemoteSHA) {
+		return violation{}, false
+	}
+
+	// R038: Published tags never move and never disappear
+	if strings.HasPrefix(u.RemoteRef, "refs/tags/") {
+		tag := strings.TrimPrefix(u.RemoteRef, "refs/tags/")
+		if isZero(u.LocalSHA) {
+			return violation{"R038", tag, "deletes a published tag",
+				"Releases are folklore once tags move. Create a new tag instead."}, true
+		}
+		if u.LocalSHA != u.RemoteSHA {
+			return violation{"R038", tag, "rewrites a published tag",
+				"Create a NEW tag (e.g. the next patch version) instead of moving this one."}, true
+		}
+		return violation{}, false
+	}
+
+	if !strings.HasPrefix(u.RemoteRef, "refs/heads/") {
+		return violation{}, false
+	}
+
+	branch := strings.TrimPrefix(u.RemoteRef, "refs/heads/")
+	if !cfg.IsProtectedBranch(branch) {
+		return violation{}, false
+	}
+
+	// R037: Deleting or force-pushing a protected branch
+	if isZero(u.LocalSHA) {
+		return violation{"R037", branch, "deletes a protected branch",
+			"Protected branches are shared history. Don't delete them from a laptop."}, true
+	}
+
+	// Without the remote commit there is nothing to compare: not known to
+	// be a force-push, but only a forced push gets past git now
+	if _, err := gitOutput("cat-file", "-e", u.RemoteSHA+"^{commit}"); err != nil {
+		return violation{"", branch, "the remote has commits you haven't fetched, can't check this push",
+			"Fetch first: git pull, then push again."}, true
+	}
+
+	if !isFastForward(u.RemoteSHA, u.LocalSHA) {
+		return violation{"R037", branch, "is not a fast-forward (force-push to a protected branch)",
+			"Use git revert for published commits, or git pull and push again."}, true
+	}
+
+	return violation{}, false
+}
+
+// isFastForward checks if the remote commit, which must exist locally, is
+// an ancestor of the local one
+func isFastForward(remoteSHA, localSHA string) bool {
+	_, err := gitOutput("merge-base", "--is-ancestor", remoteSHA, localSHA)
+	return err == nil
+}
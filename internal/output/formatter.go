@@ -50,6 +50,10 @@ func FormatHuman(advice []model.Advice, showSuppressed bool, cfg *config.Config)
 				}
 			}
 
+			for _, e := range a.Evidence {
+				sb.WriteString(fmt.Sprintf("  • %s\n", e))
+			}
+
 			sb.WriteString(fmt.Sprintf("  Command: %s\n\n", a.Command))
 		}
 	}
@@ -115,6 +119,53 @@ func FormatCompact(advice []model.Advice) string {
 	return fmt.Sprintf("→ %s", strings.Join(active, ", "))
 }
 
+// FormatHistory returns the per-commit publication map
+func FormatHistory(state model.RepoState) string {
+	var sb strings.Builder
+
+	if len(state.PublicationMap) == 0 {
+		if state.LastCommitPushed {
+			sb.WriteString("Every commit up to HEAD is on a remote, nothing is safe to rewrite.\n")
+		} else {
+			sb.WriteString("No commits yet.\n")
+		}
+		return sb.String()
+	}
+
+	sb.WriteString("Git Next - Who Has Each Commit\n")
+	sb.WriteString("═══════════════════════════════\n\n")
+
+	for _, c := range state.PublicationMap {
+		marker := "✓ safe  "
+		if c.Published() {
+			marker = "✗ unsafe"
+		} else if c.SharedLocally() {
+			marker = "! shared"
+		}
+
+		sb.WriteString(fmt.Sprintf("%s  %s %s\n", marker, c.Hash[:7], c.Subject))
+
+		if len(c.RemoteBranches) > 0 {
+			sb.WriteString(fmt.Sprintf("            remote: %s\n", strings.Join(c.RemoteBranches, ", ")))
+		}
+		if len(c.LocalBranches) > 0 {
+			sb.WriteString(fmt.Sprintf("            branches: %s\n", strings.Join(c.LocalBranches, ", ")))
+		}
+		if len(c.Tags) > 0 {
+			sb.WriteString(fmt.Sprintf("            tags: %s\n", strings.Join(c.Tags, ", ")))
+		}
+		if len(c.Stashes) > 0 {
+			sb.WriteString(fmt.Sprintf("            stashes: %s\n", strings.Join(c.Stashes, ", ")))
+		}
+	}
+
+	sb.WriteString("\n───────────────────────────────\n")
+	sb.WriteString(fmt.Sprintf("Safe to rewrite: newest %d commit(s)\n", state.UnpublishedCount))
+	sb.WriteString("(unsafe = on a remote, shared = other local refs keep the old commit)\n")
+
+	return sb.String()
+}
+
 // getBranchList retrieves branch information for branch cleanup rules
 func getBranchList(ruleID string, cfg *config.Config) []string {
 	var branches []string
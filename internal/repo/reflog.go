@@ -0,0 +1,118 @@
+package repo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReflogEntry is one move of a ref as recorded in its reflog
+type ReflogEntry struct {
+	Selector string // e.g. HEAD@{3}
+	Hash     string // where the ref pointed after the move
+	Message  string // raw reflog message, e.g. "reset: moving to HEAD~1"
+	Time     time.Time
+}
+
+// reflogLabels turns reflog message prefixes into readable labels,
+// longest prefixes first. Catch-all prefixes keep only the text after
+// the first colon as detail ("rebase -i (pick): Fix x" -> "rebase step: Fix x").
+var reflogLabels = []struct {
+	prefix, label string
+	afterColon    bool
+}{
+	{"commit (amend):", "commit (amend)", false},
+	{"commit (initial):", "first commit", false},
+	{"commit (merge):", "merge commit", false},
+	{"commit:", "commit", false},
+	{"rebase (finish):", "rebase finished", false},
+	{"rebase -i (finish):", "rebase finished", false},
+	{"rebase (abort):", "rebase aborted", false},
+	{"rebase -i (abort):", "rebase aborted", false},
+	{"rebase (start):", "rebase started", false},
+	{"rebase -i (start):", "rebase started", false},
+	{"rebase", "rebase step", true},
+	{"reset: moving to", "reset: moving to", false},
+	{"checkout: moving from", "checkout", false},
+	{"pull", "pull", true},
+	{"merge", "merge", false},
+	{"cherry-pick", "cherry-pick", false},
+	{"revert", "revert", false},
+	{"am:", "am", false},
+	{"branch: Created from", "branch created", false},
+	{"branch: Reset to", "branch reset", false},
+	{"Branch: renamed", "branch renamed", false},
+}
+
+// Label returns a readable description of the reflog entry
+func (e ReflogEntry) Label() string {
+	for _, l := range reflogLabels {
+		if !strings.HasPrefix(e.Message, l.prefix) {
+			continue
+		}
+		detail := strings.TrimPrefix(e.Message, l.prefix)
+		if l.afterColon {
+			if idx := strings.Index(detail, ": "); idx >= 0 {
+				detail = detail[idx+2:]
+			}
+		}
+		detail = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(detail), ":"))
+		if detail == "" {
+			return l.label
+		}
+		// Labels that already end in a phrase ("reset: moving to") read on without a colon
+		if strings.Contains(l.label, ":") {
+			return l.label + " " + detail
+		}
+		return l.label + ": " + detail
+	}
+	return e.Message
+}
+
+// IsRewrite reports whether the move replaced commits instead of adding to them
+func (e ReflogEntry) IsRewrite() bool {
+	return strings.HasPrefix(e.Message, "rebase") ||
+		strings.HasPrefix(e.Message, "commit (amend)") ||
+		strings.HasPrefix(e.Message, "reset:") ||
+		strings.Contains(e.Message, "filter-branch")
+}
+
+// ReadReflog returns the newest limit entries of a ref's reflog, newest
+// first. A limit of 0 reads the whole reflog.
+func ReadReflog(ref string, limit int) ([]ReflogEntry, error) {
+	args := []string{"reflog", "show", "--date=unix", "--format=%H%x00%gd%x00%gs"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	args = append(args, ref, "--")
+
+	output, err := gitOutput("git", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reflog of %s: %w", ref, err)
+	}
+
+	var entries []ReflogEntry
+	for i, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		// --date=unix turns the selector into ref@{<timestamp>}
+		var when time.Time
+		if open := strings.LastIndex(parts[1], "@{"); open >= 0 {
+			ts, _ := strconv.ParseInt(strings.TrimSuffix(parts[1][open+2:], "}"), 10, 64)
+			when = time.Unix(ts, 0)
+		}
+
+		entries = append(entries, ReflogEntry{
+			Selector: fmt.Sprintf("%s@{%d}", ref, i),
+			Hash:     parts[0],
+			Message:  parts[2],
+			Time:     when,
+		})
+	}
+
+	return entries, nil
+}
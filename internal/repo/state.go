@@ -15,6 +15,53 @@ import (
 
 // CollectState gathers the current repository state
 func CollectState(cfg *config.Config) (model.RepoState, error) {
+	return collectState(cfg, scope{})
+}
+
+// CollectStateFor gathers only the state the given rules read. Hooks use it
+// to stay fast: the base status is always collected, the costly scans only
+// when one of their rules is asked for.
+func CollectStateFor(cfg *config.Config, ruleIDs []string) (model.RepoState, error) {
+	return collectState(cfg, newScope(ruleIDs, false))
+}
+
+// CollectStagedStateFor is CollectStateFor for pre-commit: the content
+// scans read the index only. A problem in a commit that is already made
+// must not block the commit that fixes it.
+func CollectStagedStateFor(cfg *config.Config, ruleIDs []string) (model.RepoState, error) {
+	return collectState(cfg, newScope(ruleIDs, true))
+}
+
+// scope is what a collection serves
+type scope struct {
+	rules  map[string]bool // nil serves all rules
+	staged bool            // scan the index, not the unpushed commits
+}
+
+// newScope serves the given rules
+func newScope(ruleIDs []string, staged bool) scope {
+	s := scope{rules: make(map[string]bool), staged: staged}
+	for _, id := range ruleIDs {
+		s.rules[id] = true
+	}
+	return s
+}
+
+// needs reports whether any of the rules is served
+func (s scope) needs(ids ...string) bool {
+	if s.rules == nil {
+		return true
+	}
+	for _, id := range ids {
+		if s.rules[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// collectState runs the collectors the scope needs
+func collectState(cfg *config.Config, s scope) (model.RepoState, error) {
 	state := model.RepoState{}
 
 	// Check if we're in a git repo
@@ -52,6 +99,13 @@ func CollectState(cfg *config.Config) (model.RepoState, error) {
 		return state, err
 	}
 
+	// Get per-commit publication map
+	if s.needs("R020", "R021", "R022", "R049", "R059", "R060", "R073", "R074") {
+		if err := collectPublicationMap(&state); err != nil {
+			return state, err
+		}
+	}
+
 	// Get merge commit status
 	if err := collectMergeCommitStatus(&state); err != nil {
 		return state, err
@@ -63,33 +117,76 @@ func CollectState(cfg *config.Config) (model.RepoState, error) {
 	}
 
 	// Get branch health status (R34-R36)
-	if err := collectBranchHealth(&state, cfg); err != nil {
-		return state, err
+	if s.needs("R034", "R035", "R036", "R061") {
+		if err := collectBranchHealth(&state, cfg); err != nil {
+			return state, err
+		}
+	}
+
+	// Get submodule health (R040, R045)
+	if s.needs("R040", "R045") {
+		if err := collectSubmodules(&state); err != nil {
+			return state, err
+		}
 	}
 
 	// Get dangerous operation status (R037-R041)
-	if err := collectDangerousOperations(&state, cfg); err != nil {
-		return state, err
+	if s.needs("R037", "R038", "R039", "R041") {
+		if err := collectDangerousOperations(&state, cfg); err != nil {
+			return state, err
+		}
+	}
+
+	// Find lock files left by crashed git processes (R070)
+	if s.needs("R070") {
+		if err := detectStaleLocks(&state, cfg); err != nil {
+			return state, err
+		}
+	}
+
+	// Scan staged changes for credentials (R071)
+	if s.needs("R071") {
+		if err := detectSecrets(&state, cfg, !s.staged); err != nil {
+			return state, err
+		}
 	}
 
 	// Get repo integrity status (R042-R046)
-	if err := collectRepoIntegrity(&state); err != nil {
+	if err := collectRepoIntegrity(&state, cfg, s); err != nil {
 		return state, err
 	}
 
+	// Find commits nothing references anymore (R066)
+	if s.needs("R066") {
+		if err := collectLostWork(&state, cfg); err != nil {
+			return state, err
+		}
+	}
+
+	// Predict conflicts of syncing with upstream and default branch
+	if s.needs("R005", "R006", "R030", "R031", "R032", "R033", "R048", "R051", "R063", "R064", "R065") {
+		if err := collectSyncPrediction(&state, cfg); err != nil {
+			return state, err
+		}
+	}
+
 	// Get workflow hygiene status (R047-R051)
-	if err := collectWorkflowHygiene(&state, cfg); err != nil {
+	if err := collectWorkflowHygiene(&state, cfg, s); err != nil {
 		return state, err
 	}
 
 	// Get mild suggestion status (R052-R055)
-	if err := collectMildSuggestions(&state); err != nil {
-		return state, err
+	if s.needs("R052", "R053", "R054", "R055") {
+		if err := collectMildSuggestions(&state); err != nil {
+			return state, err
+		}
 	}
 
 	// Get informational status (R056-R058)
-	if err := collectInformational(&state); err != nil {
-		return state, err
+	if s.needs("R056", "R057", "R058") {
+		if err := collectInformational(&state); err != nil {
+			return state, err
+		}
 	}
 
 	return state, nil
@@ -101,7 +198,8 @@ func collectWorkingTreeStatus(state *model.RepoState) error {
 		return err
 	}
 
-	lines := strings.Split(strings.TrimSpace(output), "\n")
+	// Only trim the trailing newline: a leading space is a meaningful status column
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
 	if len(lines) == 1 && lines[0] == "" {
 		return nil
 	}
@@ -117,24 +215,46 @@ func collectWorkingTreeStatus(state *model.RepoState) error {
 		// Staged files (index modified)
 		if indexStatus != ' ' && indexStatus != '?' {
 			state.StagedFiles++
+			state.StagedPaths = append(state.StagedPaths, porcelainPath(line))
 		}
 
 		// Modified files (work tree modified)
 		if workTreeStatus == 'M' {
 			state.ModifiedFiles++
+			state.ModifiedPaths = append(state.ModifiedPaths, porcelainPath(line))
+		}
+
+		// Deleted in the work tree but not staged
+		if workTreeStatus == 'D' {
+			state.DeletedPaths = append(state.DeletedPaths, porcelainPath(line))
 		}
 
 		// Untracked files
 		if indexStatus == '?' && workTreeStatus == '?' {
 			state.UntrackedFiles++
+			state.UntrackedPaths = append(state.UntrackedPaths, porcelainPath(line))
 		}
 	}
 
-	state.Dirty = state.StagedFiles > 0 || state.ModifiedFiles > 0 || state.UntrackedFiles > 0
+	state.Dirty = state.StagedFiles > 0 || state.ModifiedFiles > 0 || state.UntrackedFiles > 0 ||
+		len(state.DeletedPaths) > 0
 
 	return nil
 }
 
+// porcelainPath extracts the path from a `git status --porcelain` line
+func porcelainPath(line string) string {
+	if len(line) < 4 {
+		return ""
+	}
+	path := line[3:]
+	// Renames are reported as "old -> new"
+	if idx := strings.Index(path, " -> "); idx >= 0 {
+		path = path[idx+4:]
+	}
+	return strings.Trim(path, "\"")
+}
+
 func collectBranchStatus(state *model.RepoState) error {
 	output, err := gitOutput("git", "status", "--branch", "--porcelain")
 	if err != nil {
@@ -208,50 +328,32 @@ func collectProtectedBranchStatus(state *model.RepoState, cfg *config.Config) er
 
 	branch = strings.TrimSpace(branch)
 
-	for _, protected := range cfg.ProtectedBranches {
-		if branch == protected {
-			state.OnProtectedBranch = true
-			return nil
-		}
-	}
+	state.OnProtectedBranch = branch != "" && cfg.IsProtectedBranch(branch)
 
 	return nil
 }
 
 func collectPushStatus(state *model.RepoState) error {
-	// Check if HEAD has been pushed to remote
+	// Check if HEAD has been pushed to remote. Every scope collects this:
+	// unpushedBase reads NoUpstream (R034 too).
 	_, err := gitOutput("git", "rev-parse", "@{u}")
 	if err != nil {
 		// No upstream configured
+		state.NoUpstream = true
 		state.LastCommitPushed = false
 		state.CommitCountSincePush = 0
 		return nil
 	}
 
-	// Check if HEAD exists on remote
-	headHash, err := gitOutput("git", "rev-parse", "HEAD")
-	if err != nil {
-		return err
-	}
-	headHash = strings.TrimSpace(headHash)
-
-	remoteHash, err := gitOutput("git", "rev-parse", "@{u}")
-	if err != nil {
-		return err
-	}
-	remoteHash = strings.TrimSpace(remoteHash)
-
-	// Count commits since last push
-	countOutput, err := gitOutput("git", "rev-list", "--count", "@{u}..HEAD")
+	// Count commits since last push along first parents, like
+	// UnpublishedCount, so the count is the N of HEAD~N
+	// (whether HEAD itself is published comes from the publication map)
+	countOutput, err := gitOutput("git", "rev-list", "--count", "--first-parent", "@{u}..HEAD")
 	if err == nil {
 		count, _ := strconv.Atoi(strings.TrimSpace(countOutput))
 		state.CommitCountSincePush = count
 	}
 
-	// Check if current HEAD is pushed
-	_, err = gitOutput("git", "branch", "-r", "--contains", headHash)
-	state.LastCommitPushed = err == nil
-
 	return nil
 }
 
@@ -259,6 +361,10 @@ func collectMergeCommitStatus(state *model.RepoState) error {
 	// Check if there are any merge commits in recent history
 	output, err := gitOutput("git", "log", "--merges", "--oneline", "-n", "10")
 	if err != nil {
+		// No commits yet: nothing merged, and pre-commit must still run
+		if _, headErr := gitOutput("git", "rev-parse", "--verify", "--quiet", "HEAD"); headErr != nil {
+			return nil
+		}
 		return err
 	}
 
@@ -279,12 +385,56 @@ func gitOutput(name string, args ...string) (string, error) {
 	return stdout.String(), nil
 }
 
+// gitOutputWithInput runs a command with the given stdin
+func gitOutputWithInput(input string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w: %s", err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
+
+// defaultBranchRef returns the remote default branch (e.g. origin/main),
+// falling back to a local main or master
+func defaultBranchRef() (string, error) {
+	if ref, err := gitOutput("git", "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimSpace(ref), nil
+	}
+
+	for _, candidate := range []string{"origin/main", "origin/master", "main", "master"} {
+		if _, err := gitOutput("git", "rev-parse", "--verify", "--quiet", candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("no default branch found")
+}
+
+// unpushedBase returns what the unpushed commits are measured against:
+// the upstream, or the default branch when there is none
+func unpushedBase(state *model.RepoState) (string, bool) {
+	if !state.NoUpstream {
+		return "@{u}", true
+	}
+	ref, err := defaultBranchRef()
+	if err != nil {
+		return "", false
+	}
+	return ref, true
+}
+
 func runGitCommand(name string, args ...string) error {
 	cmd := exec.Command(name, args...)
 	return cmd.Run()
 }
 
-// collectActiveOperations detects ongoing git operations (R9-R11)
+// collectActiveOperations detects ongoing git operations (R9-R11, R067-R069)
 func collectActiveOperations(state *model.RepoState) error {
 	// Get git directory path
 	gitDir, err := gitOutput("git", "rev-parse", "--git-dir")
@@ -299,10 +449,13 @@ func collectActiveOperations(state *model.RepoState) error {
 		state.MergeInProgress = true
 	}
 
-	// Check for rebase in progress
+	// Check for rebase or am in progress
+	// (git am uses rebase-apply too, and marks it with an "applying" file)
 	rebaseMergePath := filepath.Join(gitDir, "rebase-merge")
 	rebaseApplyPath := filepath.Join(gitDir, "rebase-apply")
-	if dirExists(rebaseMergePath) || dirExists(rebaseApplyPath) {
+	if fileExists(filepath.Join(rebaseApplyPath, "applying")) {
+		state.AmInProgress = true
+	} else if dirExists(rebaseMergePath) || dirExists(rebaseApplyPath) {
 		state.RebaseInProgress = true
 	}
 
@@ -312,9 +465,50 @@ func collectActiveOperations(state *model.RepoState) error {
 		state.CherryPickInProgress = true
 	}
 
+	// Check for revert in progress
+	if fileExists(filepath.Join(gitDir, "REVERT_HEAD")) {
+		state.RevertInProgress = true
+	}
+
+	// Check for bisect in progress
+	if fileExists(filepath.Join(gitDir, "BISECT_LOG")) {
+		state.BisectInProgress = true
+	}
+
+	// Check for a multi-commit cherry-pick or revert with commits left.
+	// It survives without CHERRY_PICK_HEAD/REVERT_HEAD when a conflict was
+	// committed by hand instead of with --continue.
+	detectSequencer(state, gitDir)
+
 	return nil
 }
 
+// detectSequencer reads the remaining sequencer todo list
+func detectSequencer(state *model.RepoState, gitDir string) {
+	content, err := os.ReadFile(filepath.Join(gitDir, "sequencer", "todo"))
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if state.SequencerOperation == "" {
+			switch fields[0] {
+			case "pick", "p":
+				state.SequencerOperation = "cherry-pick"
+			case "revert":
+				state.SequencerOperation = "revert"
+			}
+		}
+		state.SequencerRemaining++
+	}
+
+	state.SequencerInProgress = state.SequencerRemaining > 0
+}
+
 // fileExists checks if a file exists
 func fileExists(path string) bool {
 	info, err := os.Stat(path)
@@ -340,10 +534,6 @@ func collectBranchHealth(state *model.RepoState, cfg *config.Config) error {
 		return nil
 	}
 
-	// R034: Check if current branch has upstream
-	_, err := gitOutput("git", "rev-parse", "--abbrev-ref", "@{u}")
-	state.NoUpstream = err != nil
-
 	// R035: Find merged branches (exclude current and protected branches)
 	currentBranch, err := gitOutput("git", "branch", "--show-current")
 	if err != nil {
@@ -372,6 +562,9 @@ func collectBranchHealth(state *model.RepoState, cfg *config.Config) error {
 		}
 	}
 
+	// R061: Find branches merged upstream by squash or rebase
+	detectSquashMergedBranches(state, cfg, currentBranch)
+
 	// R036: Find gone branches (remote deleted but local remains)
 	branchOutput, err := gitOutput("git", "branch", "-vv")
 	if err == nil {
@@ -397,3 +590,151 @@ func collectBranchHealth(state *model.RepoState, cfg *config.Config) error {
 
 	return nil
 }
+
+// detectSquashMergedBranches finds branches whose changes landed on the default
+// branch by squash or rebase merge. git branch --merged misses these, and
+// git branch -d refuses to delete them. The default branch is hashed once,
+// then each branch only hashes its own commits.
+func detectSquashMergedBranches(state *model.RepoState, cfg *config.Config, currentBranch string) {
+	defaultBranch, err := defaultBranchRef()
+	if err != nil {
+		return
+	}
+
+	// Most recently used branches first, so the cap drops the stale ones
+	branches, err := gitOutput("git", "for-each-ref", "--sort=-committerdate", "--format=%(refname:short)", "refs/heads")
+	if err != nil {
+		return
+	}
+
+	skip := make(map[string]bool)
+	skip[currentBranch] = true
+	for _, b := range cfg.ProtectedBranches {
+		skip[b] = true
+	}
+	for _, b := range state.MergedBranches {
+		skip[b] = true
+	}
+
+	maxBranches := cfg.GetIntParam("R061", "max_branches", 50)
+	var candidates []string
+	for _, branch := range strings.Fields(branches) {
+		if skip[branch] || branch == strings.TrimPrefix(defaultBranch, "origin/") {
+			continue
+		}
+		if len(candidates) == maxBranches {
+			break
+		}
+		candidates = append(candidates, branch)
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	upstream := loadUpstreamPatches(defaultBranch, cfg.GetIntParam("R061", "max_upstream_commits", 1000))
+	for _, branch := range candidates {
+		mergeBase, err := gitOutput("git", "merge-base", defaultBranch, branch)
+		if err != nil {
+			continue
+		}
+		mergeBase = strings.TrimSpace(mergeBase)
+
+		if upstream.rebaseMerged(mergeBase, branch) || upstream.squashMerged(mergeBase, branch) {
+			state.SquashMergedBranches = append(state.SquashMergedBranches, branch)
+		}
+	}
+}
+
+// upstreamPatches indexes recent default branch commits by tree and by
+// patch-id, mapping each to the commit that has it
+type upstreamPatches struct {
+	trees   map[string]string
+	patches map[string]string
+}
+
+// loadUpstreamPatches hashes the newest commits of the default branch
+func loadUpstreamPatches(defaultBranch string, limit int) upstreamPatches {
+	u := upstreamPatches{trees: make(map[string]string), patches: make(map[string]string)}
+	n := strconv.Itoa(limit)
+
+	if trees, err := gitOutput("git", "log", "--format=%H %T", "-n", n, defaultBranch); err == nil {
+		for _, line := range strings.Split(strings.TrimSpace(trees), "\n") {
+			if fields := strings.Fields(line); len(fields) == 2 {
+				u.trees[fields[1]] = fields[0]
+			}
+		}
+	}
+
+	if log, err := gitOutput("git", "log", "-p", "--no-merges", "-n", n, defaultBranch); err == nil {
+		for id, commit := range patchIDs(log) {
+			u.patches[id] = commit
+		}
+	}
+	return u
+}
+
+// landedAfter checks that an upstream commit came after the branch forked,
+// so a commit the branch started from doesn't count as its merge
+func landedAfter(commit, mergeBase string) bool {
+	return commit != "" && runGitCommand("git", "merge-base", "--is-ancestor", commit, mergeBase) != nil
+}
+
+// rebaseMerged checks if every commit of branch has a patch-equivalent
+// commit on the default branch
+func (u upstreamPatches) rebaseMerged(mergeBase, branch string) bool {
+	log, err := gitOutput("git", "log", "-p", "--no-merges", mergeBase+".."+branch)
+	if err != nil {
+		return false
+	}
+	ids := patchIDs(log)
+	if len(ids) == 0 {
+		return false
+	}
+	for id := range ids {
+		if !landedAfter(u.patches[id], mergeBase) {
+			return false
+		}
+	}
+	return true
+}
+
+// squashMerged checks if the whole branch landed as a single commit on the
+// default branch, by tree equality or by patch-id of the combined diff
+func (u upstreamPatches) squashMerged(mergeBase, branch string) bool {
+	// Tree equality: the squash commit has exactly the branch's tree
+	branchTree, err := gitOutput("git", "rev-parse", branch+"^{tree}")
+	if err != nil {
+		return false
+	}
+	if landedAfter(u.trees[strings.TrimSpace(branchTree)], mergeBase) {
+		return true
+	}
+
+	// Patch-id equivalence of the whole branch diff against single commits
+	diff, err := gitOutput("git", "diff", mergeBase, branch)
+	if err != nil || strings.TrimSpace(diff) == "" {
+		return false
+	}
+	for id := range patchIDs(diff) {
+		if landedAfter(u.patches[id], mergeBase) {
+			return true
+		}
+	}
+	return false
+}
+
+// patchIDs maps the stable patch-id of each patch in a diff or log -p
+// output to its commit (the zero hash for a plain diff)
+func patchIDs(patches string) map[string]string {
+	ids := make(map[string]string)
+	output, err := gitOutputWithInput(patches, "git", "patch-id", "--stable")
+	if err != nil {
+		return ids
+	}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			ids[fields[0]] = fields[1]
+		}
+	}
+	return ids
+}
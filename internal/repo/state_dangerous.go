@@ -24,11 +24,6 @@ func collectDangerousOperations(state *model.RepoState, cfg *config.Config) erro
 		return err
 	}
 
-	// R040: Submodule pointer rewrite without update
-	if err := detectSubmoduleRewrite(state); err != nil {
-		return err
-	}
-
 	// R041: Accidental history rewrite
 	if err := detectHistoryRewrite(state); err != nil {
 		return err
@@ -54,17 +49,12 @@ func detectForcePushToShared(state *model.RepoState, cfg *config.Config) error {
 	if strings.Contains(reflog, "rebase") ||
 	   strings.Contains(reflog, "amend") ||
 	   strings.Contains(reflog, "filter-branch") {
-		// Check if HEAD exists on remote
-		headHash, err := gitOutput("git", "rev-parse", "HEAD")
-		if err != nil {
+		// After a rewrite, pushing needs --force when the upstream
+		// is no longer an ancestor of HEAD
+		if _, err := gitOutput("git", "rev-parse", "--verify", "--quiet", "@{u}"); err != nil {
 			return nil
 		}
-		headHash = strings.TrimSpace(headHash)
-
-		// Check if HEAD is on remote
-		_, err = gitOutput("git", "branch", "-r", "--contains", headHash)
-		if err != nil {
-			// HEAD not on remote = would need force push
+		if _, err := gitOutput("git", "merge-base", "--is-ancestor", "@{u}", "HEAD"); err != nil {
 			state.ForcePushToShared = true
 		}
 	}
@@ -133,62 +123,31 @@ func detectResetOnProtected(state *model.RepoState, cfg *config.Config) error {
 	return nil
 }
 
-// detectSubmoduleRewrite checks for submodule pointer changes without updates
-func detectSubmoduleRewrite(state *model.RepoState) error {
-	// Check if repo has submodules
-	_, err := gitOutput("git", "config", "--file", ".gitmodules", "--get-regexp", "path")
-	if err != nil {
-		return nil // No submodules
-	}
-
-	// Check if any submodules were modified in the index
-	status, err := gitOutput("git", "status", "--porcelain")
-	if err != nil {
-		return nil
-	}
-
-	lines := strings.Split(strings.TrimSpace(status), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "M ") && strings.Contains(line, "/") {
-			// Check if this is a submodule
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				// Simple heuristic: if it's a directory in git status, might be submodule
-				state.SubmoduleRewriteNoUpdate = true
-				break
-			}
-		}
-	}
-
-	return nil
-}
-
-// detectHistoryRewrite detects accidental history rewrites
+// detectHistoryRewrite detects rewrites that dropped published commits from HEAD
 func detectHistoryRewrite(state *model.RepoState) error {
-	// Check if recent commits were rebased after being pulled by others
-	// This is detected by checking reflog for rebase after commits were pushed
-
-	reflog, err := gitOutput("git", "reflog", "-10", "--format=%gs||%gd")
+	entries, err := ReadReflog("HEAD", 10)
 	if err != nil {
 		return nil
 	}
 
-	lines := strings.Split(strings.TrimSpace(reflog), "\n")
-	foundPush := false
-	for _, line := range lines {
-		parts := strings.Split(line, "||")
-		if len(parts) < 1 {
+	for i, entry := range entries {
+		if i+1 >= len(entries) || !entry.IsRewrite() {
 			continue
 		}
 
-		message := parts[0]
+		// The older neighbour is where HEAD was before the rewrite
+		before := entries[i+1].Hash
 
-		if strings.Contains(message, "push") {
-			foundPush = true
+		// Still part of HEAD: nothing was rewritten away
+		if _, err := gitOutput("git", "merge-base", "--is-ancestor", before, "HEAD"); err == nil {
+			continue
 		}
 
-		if foundPush && (strings.Contains(message, "rebase") || strings.Contains(message, "filter-branch")) {
+		// Only a problem if someone else could have it
+		remotes, err := gitOutput("git", "branch", "-r", "--contains", before)
+		if err == nil && strings.TrimSpace(remotes) != "" {
 			state.AccidentalHistoryRewrite = true
+			state.RewrittenPublishedCommit = before
 			break
 		}
 	}
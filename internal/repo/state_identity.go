@@ -0,0 +1,127 @@
+package repo
+
+import (
+	"os"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/config"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// detectIdentity checks the configured user.email and the author and
+// committer of every unpushed commit against the identity policy (R073)
+func detectIdentity(state *model.RepoState, cfg *config.Config) error {
+	remote := identityRemote()
+	if remote != "" {
+		url, _ := gitOutput("git", "remote", "get-url", remote)
+		state.IdentityRemote = strings.TrimSpace(url)
+	}
+	allowed, hasPolicy := cfg.AllowedEmails(state.IdentityRemote)
+	state.AllowedEmails = allowed
+
+	isWrong := func(email string) bool {
+		return generatedEmail(email) || (hasPolicy && !config.IsEmailAllowed(email, allowed))
+	}
+
+	// The identity the next commit gets
+	configured, _ := gitOutput("git", "config", "user.email")
+	configured = strings.TrimSpace(configured)
+	ident, _ := gitOutput("git", "var", "GIT_AUTHOR_IDENT")
+	state.IdentityEmail = identEmail(ident)
+
+	switch {
+	case configured == "" && os.Getenv("EMAIL") == "" && os.Getenv("GIT_AUTHOR_EMAIL") == "":
+		state.IdentityProblem = "user.email is not set"
+	case generatedEmail(state.IdentityEmail):
+		state.IdentityProblem = "user.email looks generated from the host name"
+	case hasPolicy && !config.IsEmailAllowed(state.IdentityEmail, allowed):
+		state.IdentityProblem = "user.email is not allowed for this remote"
+	}
+
+	// Without a remote everything is unpushed, and there is no policy to check
+	if remote == "" {
+		return nil
+	}
+
+	// First parents only, so Depth matches HEAD~n and the publication map
+	output, err := gitOutput("git", "log", "--first-parent", "--format=%H%x00%ae%x00%ce%x00%s", "HEAD", "--not", "--remotes")
+	if err != nil || strings.TrimSpace(output) == "" {
+		return nil
+	}
+
+	for i, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.SplitN(line, "\x00", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		commit := model.IdentityCommit{Hash: parts[0], Subject: parts[3], Depth: i + 1}
+		switch {
+		case isWrong(parts[1]):
+			commit.Role, commit.Email = "author", parts[1]
+		case isWrong(parts[2]):
+			commit.Role, commit.Email = "committer", parts[2]
+		default:
+			continue
+		}
+		state.IdentityCommits = append(state.IdentityCommits, commit)
+	}
+
+	return nil
+}
+
+// identityRemote returns the remote the current branch pushes to: its
+// upstream's remote, else origin, else the first remote
+func identityRemote() string {
+	if branch, err := gitOutput("git", "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		if remote, err := gitOutput("git", "config", "branch."+strings.TrimSpace(branch)+".remote"); err == nil {
+			if r := strings.TrimSpace(remote); r != "" && r != "." {
+				return r
+			}
+		}
+	}
+
+	remotes, err := gitOutput("git", "remote")
+	if err != nil {
+		return ""
+	}
+	names := strings.Fields(remotes)
+	for _, name := range names {
+		if name == "origin" {
+			return name
+		}
+	}
+	if len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// identEmail extracts the email from "Name <email> timestamp zone"
+func identEmail(ident string) string {
+	start := strings.Index(ident, "<")
+	end := strings.Index(ident, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return ident[start+1 : end]
+}
+
+// generatedEmail recognizes the user@host addresses git makes up when
+// user.email is not set
+func generatedEmail(email string) bool {
+	lower := strings.ToLower(email)
+	if lower == "" || strings.Contains(lower, "(none)") {
+		return true
+	}
+	for _, suffix := range []string{".local", ".localdomain", "@localhost"} {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		if strings.HasSuffix(lower, "@"+strings.ToLower(host)) {
+			return true
+		}
+	}
+	return false
+}
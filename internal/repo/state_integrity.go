@@ -1,179 +1,294 @@
 package repo
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/VectorSophie/git-next/internal/config"
 	"github.com/VectorSophie/git-next/pkg/model"
 )
 
 // collectRepoIntegrity detects repo integrity issues (R042-R046)
-func collectRepoIntegrity(state *model.RepoState) error {
+func collectRepoIntegrity(state *model.RepoState, cfg *config.Config, s scope) error {
 	// R042: Conflicted files staged
-	if err := detectConflictedStaged(state); err != nil {
-		return err
+	if s.needs("R042") {
+		if err := detectConflictedStaged(state); err != nil {
+			return err
+		}
 	}
 
 	// R043: Binary files without LFS
-	if err := detectLargeBinaries(state); err != nil {
-		return err
+	if s.needs("R043") {
+		if err := detectLargeBinaries(state, cfg, !s.staged); err != nil {
+			return err
+		}
 	}
 
 	// R044: Line ending conflicts
-	if err := detectLineEndingConflict(state); err != nil {
-		return err
+	if s.needs("R044") {
+		if err := detectLineEndingConflict(state); err != nil {
+			return err
+		}
 	}
 
-	// R045: Submodule detached HEAD
-	if err := detectSubmoduleDetached(state); err != nil {
-		return err
+	// R046: Shallow clone doing history ops
+	if s.needs("R046") {
+		if err := detectShallowCloneHistoryOps(state); err != nil {
+			return err
+		}
 	}
 
-	// R046: Shallow clone doing history ops
-	if err := detectShallowCloneHistoryOps(state); err != nil {
-		return err
+	// R062: Upstream force-pushed under us
+	if s.needs("R062") {
+		if err := detectUpstreamRewrite(state); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// detectConflictedStaged checks for conflict markers in staged files
+// detectConflictedStaged checks the staged content of files for conflict
+// markers. Only complete, line-anchored marker sequences count, so setext
+// headings and reST underlines made of "=" are not conflicts.
 func detectConflictedStaged(state *model.RepoState) error {
-	// Get staged files
-	staged, err := gitOutput("git", "diff", "--cached", "--name-only")
-	if err != nil || strings.TrimSpace(staged) == "" {
+	// Binaries show up as "-\t-\t" in numstat
+	numstat, err := gitOutput("git", "diff", "--cached", "--numstat", "-z", "--no-renames", "--diff-filter=AM")
+	if err != nil || numstat == "" {
 		return nil
 	}
 
-	files := strings.Split(strings.TrimSpace(staged), "\n")
-	for _, file := range files {
-		file = strings.TrimSpace(file)
-		if file == "" {
+	var files []string
+	for _, entry := range strings.Split(numstat, "\x00") {
+		parts := strings.SplitN(entry, "\t", 3)
+		if len(parts) != 3 || parts[0] == "-" {
 			continue
 		}
+		files = append(files, parts[2])
+	}
+	if len(files) == 0 {
+		return nil
+	}
 
-		// Check file for conflict markers
-		content, err := os.ReadFile(file)
+	sizes := conflictMarkerSizes(files)
+	for _, file := range files {
+		// The index is what gets committed, not the working tree
+		content, err := gitOutput("git", "show", ":"+file)
 		if err != nil {
 			continue
 		}
 
-		contentStr := string(content)
-		if strings.Contains(contentStr, "<<<<<<<") ||
-		   strings.Contains(contentStr, "=======") ||
-		   strings.Contains(contentStr, ">>>>>>>") {
-			state.ConflictedFilesStaged = true
-			state.ConflictedFiles = append(state.ConflictedFiles, file)
+		blocks := findConflictBlocks(content, sizes[file])
+		if len(blocks) == 0 {
+			continue
+		}
+		state.ConflictedFilesStaged = true
+		state.ConflictedFiles = append(state.ConflictedFiles, file)
+		for _, b := range blocks {
+			state.ConflictMarkers = append(state.ConflictMarkers, fmt.Sprintf("%s:%d-%d", file, b[0], b[1]))
 		}
 	}
 
 	return nil
 }
 
-// detectLargeBinaries checks for large binary files without LFS
-func detectLargeBinaries(state *model.RepoState) error {
-	// Check if LFS is installed
-	_, lfsErr := gitOutput("git", "lfs", "version")
-	hasLFS := lfsErr == nil
+// conflictMarkerSizes reads the conflict-marker-size attribute of each file
+// from the index, defaulting to git's 7
+func conflictMarkerSizes(files []string) map[string]int {
+	sizes := make(map[string]int)
+	for _, file := range files {
+		sizes[file] = 7
+	}
 
-	// Get recently added/modified files
-	recent, err := gitOutput("git", "diff", "--cached", "--name-only", "--diff-filter=AM")
-	if err != nil || strings.TrimSpace(recent) == "" {
-		return nil
+	// check-attr takes paths relative to the current directory, diff gives them from the top
+	top, err := gitOutput("git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return sizes
+	}
+	output, err := gitOutputWithInput(strings.Join(files, "\x00")+"\x00",
+		"git", "-C", strings.TrimSpace(top), "check-attr", "--cached", "-z", "--stdin", "conflict-marker-size")
+	if err != nil {
+		return sizes
 	}
 
-	files := strings.Split(strings.TrimSpace(recent), "\n")
-	for _, file := range files {
-		file = strings.TrimSpace(file)
-		if file == "" {
-			continue
+	// -z output is path NUL attribute NUL value NUL
+	fields := strings.Split(output, "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		if n, err := strconv.Atoi(fields[i+2]); err == nil && n > 0 {
+			sizes[fields[i]] = n
 		}
+	}
+	return sizes
+}
 
-		// Check file size
-		info, err := os.Stat(file)
-		if err != nil {
-			continue
-		}
-
-		// If file > 1MB and binary and not tracked by LFS
-		if info.Size() > 1024*1024 {
-			// Check if binary
-			content, err := os.ReadFile(file)
-			if err != nil {
-				continue
-			}
-
-			// Simple binary check: look for null bytes
-			isBinary := false
-			for _, b := range content[:min(512, len(content))] {
-				if b == 0 {
-					isBinary = true
-					break
-				}
-			}
+// findConflictBlocks returns the first and last line of every conflict
+// block: "<<<<<<< ", optionally "||||||| ", then "=======" alone on its
+// line, then ">>>>>>> ", in that order
+func findConflictBlocks(content string, size int) [][2]int {
+	ours := strings.Repeat("<", size)
+	base := strings.Repeat("|", size)
+	sep := strings.Repeat("=", size)
+	theirs := strings.Repeat(">", size)
+
+	isMarker := func(line, marker string) bool {
+		// git always writes a label after the opening and closing markers
+		return strings.HasPrefix(line, marker+" ") || line == marker
+	}
 
-			if isBinary {
-				// Check if tracked by LFS
-				if hasLFS {
-					lfsCheck, _ := gitOutput("git", "lfs", "ls-files", file)
-					if strings.TrimSpace(lfsCheck) == "" {
-						state.LargeBinariesWithoutLFS = true
-						state.LargeBinaryFiles = append(state.LargeBinaryFiles, file)
-					}
-				} else {
-					state.LargeBinariesWithoutLFS = true
-					state.LargeBinaryFiles = append(state.LargeBinaryFiles, file)
-				}
-			}
+	var blocks [][2]int
+	const (
+		outside = iota
+		inOurs
+		inTheirs
+	)
+	phase := outside
+	start := 0
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case isMarker(line, ours):
+			// A new opening marker restarts the block
+			phase, start = inOurs, i+1
+		case phase == inOurs && isMarker(line, base):
+		case phase == inOurs && line == sep:
+			phase = inTheirs
+		case phase == inTheirs && isMarker(line, theirs):
+			blocks = append(blocks, [2]int{start, i + 1})
+			phase = outside
 		}
 	}
-
-	return nil
+	return blocks
 }
 
-// detectLineEndingConflict checks for CRLF/LF inconsistencies
+// detectLineEndingConflict analyzes git ls-files --eol for files whose
+// line endings fight the attributes or each other (R044)
 func detectLineEndingConflict(state *model.RepoState) error {
-	// Check git config for core.autocrlf
+	output, err := gitOutput("git", "-c", "core.quotePath=false", "ls-files", "--eol", "-z")
+	if err != nil || output == "" {
+		return nil
+	}
+
 	autocrlf, _ := gitOutput("git", "config", "core.autocrlf")
 	autocrlf = strings.TrimSpace(autocrlf)
+	coreEOL, _ := gitOutput("git", "config", "core.eol")
+	coreEOL = strings.TrimSpace(coreEOL)
 
-	// Check recent warnings about line endings
-	status, err := gitOutput("git", "status")
-	if err != nil {
+	eolDiffOnly := eolOnlyChanges()
+	needAttributes := make(map[string]bool)
+
+	for _, entry := range strings.Split(output, "\x00") {
+		// i/<index> w/<worktree> attr/<attributes>\t<path>
+		tab := strings.Index(entry, "\t")
+		if tab < 0 {
+			continue
+		}
+		file := entry[tab+1:]
+		fields := strings.Fields(entry[:tab])
+		if len(fields) < 2 {
+			continue
+		}
+		index := strings.TrimPrefix(fields[0], "i/")
+		worktree := strings.TrimPrefix(fields[1], "w/")
+		attr := ""
+		if at := strings.Index(entry[:tab], "attr/"); at >= 0 {
+			attr = strings.TrimSpace(entry[at+len("attr/") : tab])
+		}
+		isText := strings.Contains(attr, "text") && !strings.Contains(attr, "-text")
+
+		problem := ""
+		switch {
+		case index == "-text" || index == "":
+			continue
+		case isText && (index == "crlf" || index == "mixed"):
+			// Attributes say text, so the index should hold LF only
+			problem = "CRLF in the index, .gitattributes says " + attr
+			if index == "mixed" {
+				problem = "mixed line endings in the index, .gitattributes says " + attr
+			}
+		case index == "mixed" || worktree == "mixed":
+			problem = "mixed line endings"
+			needAttributes[eolPattern(file)] = true
+		case eolDiffOnly[file]:
+			problem = fmt.Sprintf("working tree differs from the index only by line endings (%s vs %s)", worktree, index)
+			if !isText {
+				needAttributes[eolPattern(file)] = true
+			}
+		default:
+			continue
+		}
+
+		state.LineEndingConflict = true
+		state.LineEndingIssues = append(state.LineEndingIssues, model.LineEndingIssue{Path: file, Problem: problem})
+	}
+
+	if !state.LineEndingConflict {
 		return nil
 	}
 
-	if strings.Contains(status, "CRLF") || strings.Contains(status, "LF") {
-		state.LineEndingConflict = true
+	// Leave checkout endings to the local config where it asks for CRLF
+	eolAttr := "text eol=lf"
+	if autocrlf == "true" || coreEOL == "crlf" {
+		eolAttr = "text"
 	}
+	for pattern := range needAttributes {
+		attr := eolAttr
+		if pattern == "*.bat" || pattern == "*.cmd" || pattern == "*.ps1" {
+			attr = "text eol=crlf"
+		}
+		state.LineEndingAttributes = append(state.LineEndingAttributes, pattern+" "+attr)
+	}
+	sort.Strings(state.LineEndingAttributes)
+
+	if autocrlf == "" {
+		autocrlf = "unset"
+	}
+	if coreEOL == "" {
+		coreEOL = "unset"
+	}
+	state.LineEndingConfig = fmt.Sprintf("core.autocrlf=%s, core.eol=%s", autocrlf, coreEOL)
 
 	return nil
 }
 
-// detectSubmoduleDetached checks if submodules are in detached HEAD
-func detectSubmoduleDetached(state *model.RepoState) error {
-	// Check if repo has submodules
-	submodules, err := gitOutput("git", "submodule", "status")
-	if err != nil || strings.TrimSpace(submodules) == "" {
-		return nil
+// eolOnlyChanges returns modified files whose diff disappears when
+// carriage returns at line ends are ignored
+func eolOnlyChanges() map[string]bool {
+	only := make(map[string]bool)
+
+	modified, err := gitOutput("git", "-c", "core.quotePath=false", "diff", "--name-only", "-z")
+	if err != nil || modified == "" {
+		return only
+	}
+	for _, file := range strings.Split(modified, "\x00") {
+		if file != "" {
+			only[file] = true
+		}
 	}
 
-	lines := strings.Split(strings.TrimSpace(submodules), "\n")
-	for _, line := range lines {
-		// Detached HEAD submodules start with a space or -
-		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "-") {
-			// Extract submodule name
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				state.SubmoduleDetachedHead = true
-				state.SubmoduleName = parts[1]
-				break
-			}
+	// --numstat leaves out files with no changes left after ignoring CRs
+	real, err := gitOutput("git", "-c", "core.quotePath=false", "diff", "--numstat", "-z", "--no-renames", "--ignore-cr-at-eol")
+	if err != nil {
+		return map[string]bool{}
+	}
+	for _, entry := range strings.Split(real, "\x00") {
+		if parts := strings.SplitN(entry, "\t", 3); len(parts) == 3 {
+			delete(only, parts[2])
 		}
 	}
+	return only
+}
 
-	return nil
+// eolPattern returns the .gitattributes pattern covering a file: its
+// extension, or the file name when it has none
+func eolPattern(file string) string {
+	if ext := filepath.Ext(file); ext != "" {
+		return "*" + ext
+	}
+	return filepath.Base(file)
 }
 
 // detectShallowCloneHistoryOps checks for history operations on shallow clone
@@ -209,9 +324,61 @@ func detectShallowCloneHistoryOps(state *model.RepoState) error {
 	return nil
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// detectUpstreamRewrite checks if the tracked branch was force-pushed since
+// this branch forked from it. A plain rebase or merge would then replay the
+// old, rewritten commits on top of the new ones.
+func detectUpstreamRewrite(state *model.RepoState) error {
+	if state.Behind == 0 || state.Ahead == 0 {
+		return nil
 	}
-	return b
+
+	upstream, err := gitOutput("git", "rev-parse", "--symbolic-full-name", "@{u}")
+	if err != nil {
+		return nil
+	}
+	upstream = strings.TrimSpace(upstream)
+
+	// The remote-tracking reflog records forced updates on fetch/pull.
+	// With --date=relative %gd is "<ref>@{2 hours ago}", when the update happened.
+	reflog, err := gitOutput("git", "reflog", "show", "-n", "20", "--date=relative", "--format=%gs||%gd", upstream)
+	if err != nil {
+		return nil
+	}
+
+	forced := ""
+	for _, line := range strings.Split(strings.TrimSpace(reflog), "\n") {
+		if strings.Contains(line, "forced-update") {
+			forced = line
+			break
+		}
+	}
+	if forced == "" {
+		return nil
+	}
+
+	// --fork-point uses the same reflog to find the old upstream commit we built on
+	forkPoint, err := gitOutput("git", "merge-base", "--fork-point", upstream, "HEAD")
+	if err != nil || strings.TrimSpace(forkPoint) == "" {
+		return nil
+	}
+	forkPoint = strings.TrimSpace(forkPoint)
+
+	// Fork point still in upstream: the rewrite didn't touch our base
+	if _, err := gitOutput("git", "merge-base", "--is-ancestor", forkPoint, upstream); err == nil {
+		return nil
+	}
+
+	parts := strings.SplitN(forced, "||", 2)
+	note := strings.TrimPrefix(upstream, "refs/remotes/") + " was " + parts[0]
+	if len(parts) == 2 {
+		if open := strings.LastIndex(parts[1], "@{"); open >= 0 && strings.HasSuffix(parts[1], "}") {
+			note += " (" + parts[1][open+2:len(parts[1])-1] + ")"
+		}
+	}
+
+	state.UpstreamRewritten = true
+	state.UpstreamForkPoint = forkPoint[:7]
+	state.UpstreamRewriteNote = note
+
+	return nil
 }
@@ -0,0 +1,191 @@
+package repo
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/config"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// lfsPointerPrefix starts every Git LFS pointer file
+const lfsPointerPrefix = "version https://git-lfs.github.com/spec/v1"
+
+// lfsPointerMaxSize is the size below which a blob can be an LFS pointer
+const lfsPointerMaxSize = 1024
+
+// blobInfo is a file version about to be committed or pushed
+type blobInfo struct {
+	path      string
+	hash      string
+	size      int64
+	binary    bool
+	committed bool
+}
+
+// detectLargeBinaries checks staged and unpushed files against the LFS
+// attributes: large binaries outside LFS, real content where LFS expects a
+// pointer, and pointers committed without LFS installed (R043).
+// scanUnpushed adds the unpushed commits to the index when the config allows.
+func detectLargeBinaries(state *model.RepoState, cfg *config.Config, scanUnpushed bool) error {
+	maxSize := int64(cfg.GetIntParam("R043", "max_file_kb", 1024)) * 1024
+
+	blobs := changedBlobs([]string{"diff", "--cached"}, false)
+	if scanUnpushed && cfg.GetBoolParam("R043", "scan_unpushed", true) {
+		if base, ok := unpushedBase(state); ok {
+			blobs = append(blobs, changedBlobs([]string{"log", "--format=", base + "..HEAD"}, true)...)
+		}
+	}
+	if len(blobs) == 0 {
+		return nil
+	}
+
+	_, lfsErr := gitOutput("git", "lfs", "version")
+	hasLFS := lfsErr == nil
+
+	paths := make([]string, len(blobs))
+	for i, b := range blobs {
+		paths[i] = b.path
+	}
+	tracked := lfsTracked(paths)
+
+	patterns := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, b := range blobs {
+		if seen[b.path+b.hash] {
+			continue
+		}
+		seen[b.path+b.hash] = true
+
+		label := b.path
+		if b.committed {
+			label += " (unpushed commit)"
+		}
+
+		pointer := b.size < lfsPointerMaxSize && isLFSPointer(b.hash)
+		switch {
+		case tracked[b.path] && !pointer && b.size > 0:
+			// The clean filter never ran: the content went into history as is.
+			// Empty files are the exception, LFS stores them as they are.
+			state.LFSSmudgedFiles = append(state.LFSSmudgedFiles, label)
+		case pointer && !hasLFS:
+			state.LFSPointersWithoutLFS = append(state.LFSPointersWithoutLFS, label)
+		case !tracked[b.path] && b.binary && b.size >= maxSize:
+			state.LargeBinaryFiles = append(state.LargeBinaryFiles, label)
+			patterns[lfsPattern(b.path)] = true
+		default:
+			continue
+		}
+		state.LargeBinariesWithoutLFS = true
+	}
+
+	for pattern := range patterns {
+		state.LFSTrackPatterns = append(state.LFSTrackPatterns, pattern)
+	}
+	sort.Strings(state.LFSTrackPatterns)
+	state.LFSInstalled = hasLFS
+
+	return nil
+}
+
+// changedBlobs lists the files added or modified by a diff or log command
+// with their blob hash, size and whether git considers them binary.
+// Nothing is read beyond what git reports.
+func changedBlobs(args []string, committed bool) []blobInfo {
+	base := []string{"-c", "core.quotePath=false"}
+
+	raw, err := gitOutput("git", append(append(base, args...), "--raw", "-z", "--no-abbrev", "--no-renames", "--diff-filter=AM")...)
+	if err != nil {
+		return nil
+	}
+
+	// :<old mode> <new mode> <old hash> <new hash> <status> NUL <path> NUL
+	var blobs []blobInfo
+	tokens := strings.Split(raw, "\x00")
+	for i := 0; i+1 < len(tokens); i++ {
+		meta := strings.TrimLeft(tokens[i], "\n")
+		if !strings.HasPrefix(meta, ":") {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) < 5 || strings.HasPrefix(fields[1], "160000") {
+			continue
+		}
+		blobs = append(blobs, blobInfo{path: tokens[i+1], hash: fields[3], committed: committed})
+		i++
+	}
+	if len(blobs) == 0 {
+		return nil
+	}
+
+	// Binary files show up as "-\t-\t<path>" in numstat
+	binary := make(map[string]bool)
+	numstat, _ := gitOutput("git", append(append(base, args...), "--numstat", "-z", "--no-renames", "--diff-filter=AM")...)
+	for _, entry := range strings.Split(numstat, "\x00") {
+		parts := strings.SplitN(strings.TrimLeft(entry, "\n"), "\t", 3)
+		if len(parts) == 3 && parts[0] == "-" {
+			binary[parts[2]] = true
+		}
+	}
+
+	var hashes strings.Builder
+	for _, b := range blobs {
+		hashes.WriteString(b.hash + "\n")
+	}
+	sizes := make(map[string]int64)
+	check, _ := gitOutputWithInput(hashes.String(), "git", "cat-file", "--batch-check")
+	for _, line := range strings.Split(check, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 {
+			sizes[fields[0]], _ = strconv.ParseInt(fields[2], 10, 64)
+		}
+	}
+
+	for i := range blobs {
+		blobs[i].size = sizes[blobs[i].hash]
+		blobs[i].binary = binary[blobs[i].path]
+	}
+	return blobs
+}
+
+// lfsTracked checks the filter attribute of all paths in one call
+func lfsTracked(paths []string) map[string]bool {
+	tracked := make(map[string]bool)
+
+	// check-attr takes paths relative to the current directory, git reports them from the top
+	top, err := gitOutput("git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return tracked
+	}
+	output, err := gitOutputWithInput(strings.Join(paths, "\x00")+"\x00",
+		"git", "-C", strings.TrimSpace(top), "check-attr", "--cached", "-z", "--stdin", "filter")
+	if err != nil {
+		return tracked
+	}
+
+	// -z output is path NUL attribute NUL value NUL
+	fields := strings.Split(output, "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		if fields[i+2] == "lfs" {
+			tracked[fields[i]] = true
+		}
+	}
+	return tracked
+}
+
+// isLFSPointer checks whether a small blob is an LFS pointer
+func isLFSPointer(hash string) bool {
+	content, err := gitOutput("git", "cat-file", "blob", hash)
+	return err == nil && strings.HasPrefix(content, lfsPointerPrefix)
+}
+
+// lfsPattern returns the git lfs track pattern for a file: its extension,
+// or the path itself when it has none
+func lfsPattern(file string) string {
+	if ext := filepath.Ext(file); ext != "" {
+		return "*" + ext
+	}
+	return file
+}
@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VectorSophie/git-next/internal/config"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// detectStaleLocks finds lock files older than min_age_minutes that no
+// running git process holds (R070)
+func detectStaleLocks(state *model.RepoState, cfg *config.Config) error {
+	gitDir, err := gitOutput("git", "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return nil
+	}
+	gitDir = strings.TrimSpace(gitDir)
+
+	locks := FindLockFiles(gitDir)
+	if len(locks) == 0 {
+		return nil
+	}
+
+	running, known := GitProcessRunning(gitDir)
+	if running {
+		// Someone is working: the locks are theirs
+		return nil
+	}
+
+	state.LockOwnerChecked = known
+
+	// /proc only shows git itself: editors using libgit2 or JGit hold locks
+	// too, so a young lock is left alone either way
+	minAge := time.Duration(cfg.GetIntParam("R070", "min_age_minutes", 10)) * time.Minute
+
+	for _, lock := range locks {
+		info, err := os.Stat(lock)
+		if err != nil {
+			continue
+		}
+		if time.Since(info.ModTime()) < minAge {
+			continue
+		}
+
+		rel, err := filepath.Rel(gitDir, lock)
+		if err != nil {
+			rel = lock
+		}
+		state.StaleLocks = append(state.StaleLocks, model.LockFile{
+			Path:      lock,
+			Name:      rel,
+			Timestamp: info.ModTime().Unix(),
+		})
+	}
+
+	return nil
+}
+
+// FindLockFiles returns the lock files git leaves behind when it dies:
+// index.lock, HEAD.lock, config.lock, packed-refs.lock and refs/**/*.lock
+func FindLockFiles(gitDir string) []string {
+	var locks []string
+	for _, name := range []string{"index.lock", "HEAD.lock", "ORIG_HEAD.lock", "config.lock", "packed-refs.lock", "shallow.lock"} {
+		path := filepath.Join(gitDir, name)
+		if fileExists(path) {
+			locks = append(locks, path)
+		}
+	}
+
+	filepath.Walk(filepath.Join(gitDir, "refs"), func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.HasSuffix(path, ".lock") {
+			locks = append(locks, path)
+		}
+		return nil
+	})
+
+	return locks
+}
+
+// GitProcessRunning checks /proc for a live git process working in this
+// repository. known is false where there is no /proc to look at.
+func GitProcessRunning(gitDir string) (running bool, known bool) {
+	if runtime.GOOS != "linux" {
+		return false, false
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return false, false
+	}
+
+	// The work tree is the parent of .git for normal repos
+	workTree := filepath.Dir(gitDir)
+	self := os.Getpid()
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == self {
+			continue
+		}
+
+		cmdline, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cmdline"))
+		if err != nil || len(cmdline) == 0 {
+			continue
+		}
+		argv0 := filepath.Base(strings.SplitN(string(cmdline), "\x00", 2)[0])
+		if argv0 != "git" && !strings.HasPrefix(argv0, "git-") {
+			continue
+		}
+		// Our own helper processes don't hold locks
+		if argv0 == "git-next" {
+			continue
+		}
+
+		cwd, err := os.Readlink(filepath.Join("/proc", entry.Name(), "cwd"))
+		if err != nil {
+			// Another user's git process: can't tell where it runs, assume the worst
+			return true, true
+		}
+		if isWithin(cwd, workTree) || isWithin(cwd, gitDir) {
+			return true, true
+		}
+	}
+
+	return false, true
+}
+
+// isWithin checks if path is dir or below it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,189 @@
+package repo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/config"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// branchTypeGroup finds the branch type alternatives in a naming pattern,
+// e.g. "(feature|bugfix|hotfix)/" or "^feature/"
+var branchTypeGroup = regexp.MustCompile(`\((?:\?:)?([A-Za-z0-9_|-]+)\)/|^\^?([A-Za-z0-9_-]+)/`)
+
+// ticketID finds a ticket reference such as ABC-123 in a branch name.
+// Underscores separate words in branch names, so \b doesn't do.
+var ticketID = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])([a-z][a-z0-9]+-[0-9]+)(?:[^0-9]|$)`)
+
+// detectBranchNaming checks the current branch and local branches that
+// were never pushed against the branch_naming policy (R072)
+func detectBranchNaming(state *model.RepoState, cfg *config.Config) error {
+	var patterns []*regexp.Regexp
+	for _, p := range cfg.BranchNaming.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid branch_naming pattern %q: %w", p, err)
+		}
+		patterns = append(patterns, re)
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	current, _ := gitOutput("git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	current = strings.TrimSpace(current)
+
+	output, err := gitOutput("git", "for-each-ref", "--format=%(refname:short)%00%(upstream)", "refs/heads")
+	if err != nil {
+		return nil
+	}
+
+	remotes, _ := gitOutput("git", "for-each-ref", "--format=%(refname:lstrip=3)", "refs/remotes")
+	pushed := make(map[string]bool)
+	for _, name := range strings.Split(strings.TrimSpace(remotes), "\n") {
+		pushed[name] = true
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.SplitN(line, "\x00", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			continue
+		}
+		branch := parts[0]
+
+		// Published branches are past the point where a rename is cheap
+		isNew := parts[1] == "" && !pushed[branch]
+		if branch != current && !isNew {
+			continue
+		}
+		if cfg.IsBranchNameExempt(branch) || matchesAny(branch, patterns) {
+			continue
+		}
+
+		violation := model.BranchNameViolation{
+			Branch:    branch,
+			Suggested: suggestBranchName(branch, cfg.BranchNaming.Patterns, patterns),
+			Current:   branch == current,
+			Published: !isNew,
+		}
+		if violation.Current {
+			state.BranchNameViolations = append([]model.BranchNameViolation{violation}, state.BranchNameViolations...)
+		} else {
+			state.BranchNameViolations = append(state.BranchNameViolations, violation)
+		}
+	}
+
+	state.BranchNamePatterns = cfg.BranchNaming.Patterns
+	return nil
+}
+
+// suggestBranchName derives a name matching the policy from an old name:
+// its ticket ID and description, under each branch type the patterns allow.
+// Returns "" when no candidate fits.
+func suggestBranchName(old string, sources []string, patterns []*regexp.Regexp) string {
+	ticket := ""
+	if m := ticketID.FindStringSubmatch(old); m != nil {
+		ticket = strings.ToUpper(m[1])
+	}
+
+	// The part after the last slash without the ticket describes the work
+	desc := old
+	oldType := ""
+	if slash := strings.LastIndex(old, "/"); slash >= 0 {
+		oldType, desc = strings.ToLower(old[:slash]), old[slash+1:]
+	}
+	if ticket != "" {
+		desc = ticketID.ReplaceAllString(desc, "-")
+	}
+	desc = slugify(desc)
+
+	var bodies []string
+	for _, body := range []string{joinNonEmpty(ticket, desc), ticket, desc, strings.ToLower(ticket), joinNonEmpty(strings.ToLower(ticket), desc)} {
+		if body != "" {
+			bodies = append(bodies, body)
+		}
+	}
+
+	var candidates []string
+	for _, t := range branchTypes(sources, oldType) {
+		for _, body := range bodies {
+			candidates = append(candidates, t+"/"+body)
+		}
+	}
+	candidates = append(candidates, bodies...)
+	candidates = append(candidates, slugify(old))
+
+	for _, c := range candidates {
+		if c != old && matchesAny(c, patterns) {
+			return c
+		}
+	}
+	return ""
+}
+
+// branchTypes lists the branch types named in the patterns. Types that
+// share a prefix with the old type come first, so "feat/x" becomes
+// "feature/x" and "bug/x" becomes "bugfix/x".
+func branchTypes(sources []string, oldType string) []string {
+	var preferred, rest []string
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		for _, m := range branchTypeGroup.FindAllStringSubmatch(source, -1) {
+			group := m[1]
+			if group == "" {
+				group = m[2]
+			}
+			for _, t := range strings.Split(group, "|") {
+				if t == "" || seen[t] {
+					continue
+				}
+				seen[t] = true
+				if oldType != "" && (strings.HasPrefix(t, oldType) || strings.HasPrefix(oldType, t)) {
+					preferred = append(preferred, t)
+				} else {
+					rest = append(rest, t)
+				}
+			}
+		}
+	}
+	return append(preferred, rest...)
+}
+
+// slugify lowercases a name and joins its words with dashes
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// joinNonEmpty joins the non-empty parts with a dash
+func joinNonEmpty(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, "-")
+}
+
+// matchesAny checks a name against a list of patterns
+func matchesAny(name string, patterns []*regexp.Regexp) bool {
+	for _, re := range patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
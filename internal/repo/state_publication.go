@@ -0,0 +1,126 @@
+package repo
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// minPublicationDepth is how many commits the publication map always covers
+const minPublicationDepth = 20
+
+// maxPublicationDepth bounds the annotated map on branches with huge
+// unpushed ranges. UnpublishedCount is not bounded by it.
+const maxPublicationDepth = 100
+
+// collectPublicationMap records, for each recent commit, which refs contain it.
+// This is "who has the history": rules that rewrite N commits are only safe
+// when none of those N commits is on a remote. Entries follow the first
+// parent, so entry i is HEAD~i.
+func collectPublicationMap(state *model.RepoState) error {
+	// Count the commits that are safe to rewrite, newest first
+	count, err := gitOutput("git", "rev-list", "--count", "--first-parent", "HEAD", "--not", "--remotes")
+	if err != nil {
+		// No commits yet
+		return nil
+	}
+	state.UnpublishedCount, _ = strconv.Atoi(strings.TrimSpace(count))
+
+	// Everything is on a remote: there is nothing to annotate for a rewrite
+	if state.UnpublishedCount == 0 {
+		state.LastCommitPushed = true
+		return nil
+	}
+
+	depth := state.UnpublishedCount + 1
+	if depth < minPublicationDepth {
+		depth = minPublicationDepth
+	}
+	if depth > maxPublicationDepth {
+		depth = maxPublicationDepth
+	}
+
+	commits, err := gitOutput("git", "log", "--first-parent", "--format=%H%x00%P%x00%s", "-n", strconv.Itoa(depth), "HEAD")
+	if err != nil || strings.TrimSpace(commits) == "" {
+		return nil
+	}
+
+	currentBranch, _ := gitOutput("git", "symbolic-ref", "--quiet", "HEAD")
+	currentBranch = strings.TrimSpace(currentBranch)
+
+	stashes := stashAncestry(depth)
+
+	for _, line := range strings.Split(strings.TrimSpace(commits), "\n") {
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		entry := model.CommitPublication{Hash: parts[0], Subject: parts[2], Merge: len(strings.Fields(parts[1])) > 1}
+
+		refs, err := gitOutput("git", "for-each-ref", "--contains", entry.Hash,
+			"--format=%(refname)", "refs/heads", "refs/remotes", "refs/tags")
+		if err == nil {
+			for _, ref := range strings.Fields(refs) {
+				switch {
+				case strings.HasPrefix(ref, "refs/remotes/"):
+					if !strings.HasSuffix(ref, "/HEAD") {
+						entry.RemoteBranches = append(entry.RemoteBranches, strings.TrimPrefix(ref, "refs/remotes/"))
+					}
+				case strings.HasPrefix(ref, "refs/heads/"):
+					if ref != currentBranch {
+						entry.LocalBranches = append(entry.LocalBranches, strings.TrimPrefix(ref, "refs/heads/"))
+					}
+				case strings.HasPrefix(ref, "refs/tags/"):
+					entry.Tags = append(entry.Tags, strings.TrimPrefix(ref, "refs/tags/"))
+				}
+			}
+		}
+
+		for _, stash := range stashes {
+			if stash.commits[entry.Hash] {
+				entry.Stashes = append(entry.Stashes, stash.name)
+			}
+		}
+
+		state.PublicationMap = append(state.PublicationMap, entry)
+	}
+
+	return nil
+}
+
+// stashCommits is the set of commits a stash entry keeps alive
+type stashCommits struct {
+	name    string
+	commits map[string]bool
+}
+
+// stashAncestry resolves the recent ancestry of every stash entry
+func stashAncestry(depth int) []stashCommits {
+	list, err := gitOutput("git", "stash", "list", "--format=%gd %H")
+	if err != nil || strings.TrimSpace(list) == "" {
+		return nil
+	}
+
+	var result []stashCommits
+	for _, line := range strings.Split(strings.TrimSpace(list), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		ancestry, err := gitOutput("git", "rev-list", "-n", strconv.Itoa(depth*2), fields[1])
+		if err != nil {
+			continue
+		}
+
+		entry := stashCommits{name: fields[0], commits: make(map[string]bool)}
+		for _, hash := range strings.Fields(ancestry) {
+			entry.commits[hash] = true
+		}
+		result = append(result, entry)
+	}
+
+	return result
+}
@@ -0,0 +1,247 @@
+package repo
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/config"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// collectLostWork finds commits no branch, tag or stash can reach anymore (R066).
+// Only the HEAD reflog is read by default: git fsck walks the whole object
+// database, so it runs when scan_unreachable is set or from git-next rescue.
+func collectLostWork(state *model.RepoState, cfg *config.Config) error {
+	return findLostCommits(state, cfg, cfg.GetBoolParam("R066", "scan_unreachable", false))
+}
+
+// CollectLostWorkDeep redoes the lost commit search including the commits
+// only git fsck finds, for the rescue browser
+func CollectLostWorkDeep(state *model.RepoState, cfg *config.Config) error {
+	return findLostCommits(state, cfg, true)
+}
+
+// findLostCommits fills LostCommits from the HEAD reflog, and from fsck
+// when deep is set
+func findLostCommits(state *model.RepoState, cfg *config.Config, deep bool) error {
+	reflogDepth := cfg.GetIntParam("R066", "reflog_depth", 100)
+	maxUnreachable := cfg.GetIntParam("R066", "max_unreachable", 50)
+
+	candidates, inReflog := reflogCandidates(reflogDepth)
+
+	// Commits in no reflog at all: dropped stashes, deleted branches never checked out.
+	// Anything in the HEAD reflog was already judged above, which keeps the
+	// originals of every amend and rebase out of the list.
+	if deep {
+		for hash, source := range unreachableCandidates(maxUnreachable) {
+			if !inReflog[hash] {
+				candidates[hash] = source
+			}
+		}
+	}
+
+	state.LostCommits = nil
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	state.LostCommits = lostTips(candidates)
+	return nil
+}
+
+// reflogCandidates returns the commits HEAD left behind through a checkout
+// or reset within the newest depth reflog entries, plus every hash in the
+// HEAD reflog
+func reflogCandidates(depth int) (map[string]string, map[string]bool) {
+	candidates := make(map[string]string)
+	inReflog := make(map[string]bool)
+
+	entries, err := ReadReflog("HEAD", 0)
+	if err != nil {
+		return candidates, inReflog
+	}
+
+	for i, entry := range entries {
+		inReflog[entry.Hash] = true
+
+		if i >= depth || i+1 >= len(entries) {
+			continue
+		}
+
+		// The older neighbour is where HEAD was before this entry
+		before := entries[i+1].Hash
+		switch {
+		case strings.HasPrefix(entry.Message, "checkout:"):
+			candidates[before] = "left by checkout"
+		case strings.HasPrefix(entry.Message, "reset:"):
+			candidates[before] = "left by reset"
+		}
+	}
+
+	return candidates, inReflog
+}
+
+// unreachableCandidates asks fsck for commits only reflogs (or nothing) keep alive.
+// Stash internals (index and untracked commits) are skipped, the stash commit
+// itself is reported as a dropped stash.
+func unreachableCandidates(limit int) map[string]string {
+	candidates := make(map[string]string)
+	if limit <= 0 {
+		return candidates
+	}
+
+	output, err := gitOutput("git", "fsck", "--unreachable", "--no-reflogs", "--no-progress")
+	if err != nil {
+		return candidates
+	}
+
+	var hashes []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "unreachable" && fields[1] == "commit" {
+			hashes = append(hashes, fields[2])
+		}
+	}
+	if len(hashes) == 0 {
+		return candidates
+	}
+
+	details, err := gitOutputWithInput(strings.Join(hashes, "\n")+"\n",
+		"git", "log", "--no-walk", "--stdin", "--format=%H%x00%ct%x00%P%x00%s")
+	if err != nil {
+		return candidates
+	}
+
+	type unreachable struct {
+		hash, source string
+		time         int64
+	}
+	var found []unreachable
+	for _, line := range strings.Split(strings.TrimSpace(details), "\n") {
+		parts := strings.SplitN(line, "\x00", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		subject := parts[3]
+		if strings.HasPrefix(subject, "index on ") || strings.HasPrefix(subject, "untracked files on ") {
+			continue
+		}
+
+		source := "unreachable"
+		if isStashSubject(subject) && len(strings.Fields(parts[2])) >= 2 {
+			source = "dropped stash"
+		}
+		ts, _ := strconv.ParseInt(parts[1], 10, 64)
+		found = append(found, unreachable{parts[0], source, ts})
+	}
+
+	// Bound the work on repos with a lot of garbage: newest first
+	sort.Slice(found, func(i, j int) bool { return found[i].time > found[j].time })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	for _, u := range found {
+		candidates[u.hash] = u.source
+	}
+
+	return candidates
+}
+
+// isStashSubject checks for the subjects git stash writes
+func isStashSubject(subject string) bool {
+	return strings.HasPrefix(subject, "WIP on ") || strings.HasPrefix(subject, "On ")
+}
+
+// lostTips walks from the candidates to everything no ref reaches and
+// returns one entry per tip, newest first
+func lostTips(candidates map[string]string) []model.LostCommit {
+	var input strings.Builder
+	for hash := range candidates {
+		input.WriteString(hash + "\n")
+	}
+
+	// Stash entries below the top are only reachable through the stash reflog
+	stashes, _ := gitOutput("git", "stash", "list", "--format=%H")
+	for _, hash := range strings.Fields(stashes) {
+		input.WriteString("^" + hash + "\n")
+	}
+
+	output, err := gitOutputWithInput(input.String(),
+		"git", "rev-list", "--stdin", "--parents", "--not", "--all")
+	if err != nil {
+		return nil
+	}
+
+	// Every lost commit with its parents
+	parents := make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 {
+			parents[fields[0]] = fields[1:]
+		}
+	}
+
+	isParent := make(map[string]bool)
+	for _, ps := range parents {
+		for _, p := range ps {
+			isParent[p] = true
+		}
+	}
+
+	var lost []model.LostCommit
+	for hash := range parents {
+		// Stash internals hang below the stash commit, don't report them twice
+		if isParent[hash] {
+			continue
+		}
+
+		source, ok := candidates[hash]
+		if !ok {
+			source = "unreachable"
+		}
+
+		info, err := gitOutput("git", "log", "-1", "--format=%ct%x00%s", hash)
+		if err != nil {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimSpace(info), "\x00", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		ts, _ := strconv.ParseInt(parts[0], 10, 64)
+
+		commits := countLost(hash, parents)
+		if source == "dropped stash" {
+			commits = 1
+		}
+
+		lost = append(lost, model.LostCommit{
+			Hash:      hash,
+			Subject:   parts[1],
+			Timestamp: ts,
+			Commits:   commits,
+			Source:    source,
+		})
+	}
+
+	sort.Slice(lost, func(i, j int) bool { return lost[i].Timestamp > lost[j].Timestamp })
+	return lost
+}
+
+// countLost counts the lost commits reachable from a tip
+func countLost(tip string, parents map[string][]string) int {
+	seen := map[string]bool{tip: true}
+	queue := []string{tip}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		for _, p := range parents[hash] {
+			if _, lost := parents[p]; lost && !seen[p] {
+				seen[p] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+	return len(seen)
+}
@@ -0,0 +1,203 @@
+package repo
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/config"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// allowMarker on a line keeps it out of the secrets scan
+const allowMarker = "git-next:allow"
+
+// secretPatterns are token formats that are secrets wherever they appear
+var secretPatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN ((RSA|DSA|EC|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`)},
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GCP API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Slack webhook", regexp.MustCompile(`https://hooks\.slack\.com/services/T[A-Za-z0-9]+/B[A-Za-z0-9]+/[A-Za-z0-9]+`)},
+}
+
+// suspiciousAssignment finds values assigned to keys that usually hold secrets
+var suspiciousAssignment = regexp.MustCompile(
+	`(?i)(password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|client[_-]?secret|credentials?)[A-Za-z0-9_.-]*["']?\s*[:=]+\s*["']?([A-Za-z0-9+/=_\-.~]{16,})`)
+
+// minSecretEntropy is the Shannon entropy in bits per character above which
+// a value assigned to a suspicious key looks generated rather than typed
+const minSecretEntropy = 3.5
+
+// placeholderValue matches values that are obviously not real secrets
+var placeholderValue = regexp.MustCompile(`(?i)(example|changeme|placeholder|dummy|xxxx|your[_-]|redacted)`)
+
+// detectSecrets scans added lines of the staged diff, and optionally of the
+// unpushed commits when scanUnpushed allows it, for credentials (R071)
+func detectSecrets(state *model.RepoState, cfg *config.Config, scanUnpushed bool) error {
+	allowPaths := cfg.GetStringListParam("R071", "allow_paths")
+	var allowMatches []*regexp.Regexp
+	for _, pattern := range cfg.GetStringListParam("R071", "allow_matches") {
+		if re, err := regexp.Compile(pattern); err == nil {
+			allowMatches = append(allowMatches, re)
+		}
+	}
+
+	keep := func(f model.SecretFinding) {
+		if pathAllowed(f.Path, allowPaths) {
+			return
+		}
+		for _, re := range allowMatches {
+			if re.MatchString(f.Match) {
+				return
+			}
+		}
+		state.SecretFindings = append(state.SecretFindings, f)
+	}
+
+	scanDiff(false, keep, "diff", "--cached")
+
+	if scanUnpushed && cfg.GetBoolParam("R071", "scan_unpushed", false) {
+		if base, ok := unpushedBase(state); ok {
+			scanDiff(true, keep, "diff", base+"...HEAD")
+		}
+	}
+
+	return nil
+}
+
+// scanDiff runs a diff and reports secrets in added lines and added files
+// with secret-looking names
+func scanDiff(committed bool, report func(model.SecretFinding), args ...string) {
+	base := []string{"-c", "core.quotePath=false"}
+
+	names, err := gitOutput("git", append(append(base, args...), "--name-only", "--diff-filter=A")...)
+	if err == nil {
+		for _, file := range strings.Split(strings.TrimSpace(names), "\n") {
+			if file != "" && IsSecretFile(file) {
+				report(model.SecretFinding{Path: file, Kind: "secret file", Match: path.Base(file), Committed: committed})
+			}
+		}
+	}
+
+	diff, err := gitOutput("git", append(append(base, args...), "-U0", "--no-color", "--no-ext-diff", "--no-prefix", "--diff-filter=d")...)
+	if err != nil {
+		return
+	}
+
+	file := ""
+	line := 0
+	inHeader := false
+	for _, text := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			inHeader = true
+		case inHeader && strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(text, "+++ ")
+		case strings.HasPrefix(text, "@@ "):
+			inHeader = false
+			line = hunkStart(text)
+		case !inHeader && strings.HasPrefix(text, "+"):
+			if kind, match := findSecret(text[1:]); kind != "" {
+				report(model.SecretFinding{Path: file, Line: line, Kind: kind, Match: match, Committed: committed})
+			}
+			line++
+		}
+	}
+}
+
+// hunkStart returns the first new-file line of a hunk header
+// like "@@ -12,0 +13,2 @@"
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0
+	}
+	start := strings.SplitN(strings.TrimPrefix(fields[2], "+"), ",", 2)[0]
+	n, _ := strconv.Atoi(start)
+	return n
+}
+
+// findSecret returns the kind and matched text of a secret on a line
+func findSecret(text string) (string, string) {
+	if strings.Contains(text, allowMarker) {
+		return "", ""
+	}
+
+	for _, p := range secretPatterns {
+		if match := p.re.FindString(text); match != "" {
+			return p.kind, match
+		}
+	}
+
+	for _, m := range suspiciousAssignment.FindAllStringSubmatch(text, -1) {
+		value := m[2]
+		if placeholderValue.MatchString(value) || shannonEntropy(value) < minSecretEntropy {
+			continue
+		}
+		return "high-entropy " + strings.ToLower(m[1]), value
+	}
+
+	return "", ""
+}
+
+// IsSecretFile recognizes files that hold credentials by name (R071)
+func IsSecretFile(file string) bool {
+	name := path.Base(file)
+	switch name {
+	case "id_rsa", "id_dsa", "id_ecdsa", "id_ed25519", ".netrc", ".pgpass", "credentials.json":
+		return true
+	}
+	if name == ".env" || strings.HasPrefix(name, ".env.") {
+		// Templates are meant to be committed
+		for _, suffix := range []string{".example", ".sample", ".template", ".dist"} {
+			if strings.HasSuffix(name, suffix) {
+				return false
+			}
+		}
+		return true
+	}
+	switch path.Ext(name) {
+	case ".pem", ".key", ".p12", ".pfx", ".keystore", ".jks":
+		return true
+	}
+	return false
+}
+
+// pathAllowed matches a path against allowlist globs, by full path or base name
+func pathAllowed(file string, globs []string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(glob, file); ok {
+			return true
+		}
+		if ok, _ := path.Match(glob, path.Base(file)); ok {
+			return true
+		}
+		// A directory entry covers everything below it
+		if strings.HasPrefix(file, strings.TrimSuffix(glob, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	entropy := 0.0
+	n := float64(len([]rune(s)))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
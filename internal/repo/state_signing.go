@@ -0,0 +1,183 @@
+package repo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/config"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// gpgKeyID matches an OpenPGP key ID or fingerprint
+var gpgKeyID = regexp.MustCompile(`^(0x)?[0-9A-Fa-f]{8,40}!?$`)
+
+// detectSigning checks the signing setup and the signatures of unpushed
+// commits when commit.gpgsign is set or the config requires signing (R074)
+func detectSigning(state *model.RepoState, cfg *config.Config) error {
+	gpgsign, _ := gitOutput("git", "config", "--type=bool", "commit.gpgsign")
+	autoSign := strings.TrimSpace(gpgsign) == "true"
+	required := cfg.GetBoolParam("R074", "required", false)
+	if !autoSign && !required {
+		return nil
+	}
+	state.SigningRequired = required
+	state.SigningAutoSign = autoSign
+
+	format, _ := gitOutput("git", "config", "gpg.format")
+	format = strings.TrimSpace(format)
+	if format == "" {
+		format = "openpgp"
+	}
+	state.SigningProblem = signingSetupProblem(format)
+
+	// Without allowed signers git reports SSH signatures as N
+	canVerifySSH := allowedSignersFile() != ""
+	if format == "ssh" && !canVerifySSH {
+		state.SigningNote = "gpg.ssh.allowedSignersFile is not set: git can't verify SSH signatures, signed commits were checked for a signature only"
+	}
+
+	// Without a remote there is no unpushed range to check
+	if remotes, err := gitOutput("git", "remote"); err != nil || strings.TrimSpace(remotes) == "" {
+		return nil
+	}
+
+	// First parents only, so Depth matches HEAD~n and the publication map
+	output, err := gitOutput("git", "log", "--first-parent", "--format=%H%x00%G?%x00%s", "HEAD", "--not", "--remotes")
+	if err != nil || strings.TrimSpace(output) == "" {
+		return nil
+	}
+
+	for i, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		commit := model.SigningCommit{Hash: parts[0], Subject: parts[2], Status: parts[1], Depth: i + 1}
+		switch parts[1] {
+		case "N":
+			if !canVerifySSH && hasSignature(parts[0]) {
+				continue
+			}
+		case "B":
+		case "E":
+			// Someone else's key: re-signing would replace their signature
+			state.SigningUnknown = append(state.SigningUnknown, commit)
+			continue
+		default:
+			continue
+		}
+		state.SigningCommits = append(state.SigningCommits, commit)
+	}
+
+	return nil
+}
+
+// signingSetupProblem returns why git cannot sign with the configured
+// format and key, empty if nothing is wrong
+func signingSetupProblem(format string) string {
+	key, _ := gitOutput("git", "config", "user.signingkey")
+	key = strings.TrimSpace(key)
+
+	switch format {
+	case "ssh":
+		if key == "" {
+			if command, _ := gitOutput("git", "config", "gpg.ssh.defaultKeyCommand"); strings.TrimSpace(command) == "" {
+				return "gpg.format is ssh but user.signingkey is not set"
+			}
+		} else if gpgKeyID.MatchString(key) {
+			return "user.signingkey looks like a GPG key ID but gpg.format is ssh"
+		} else if !sshKeyLiteral(key) {
+			if _, err := os.Stat(expandHome(key)); err != nil {
+				return "user.signingkey points to " + key + ", which does not exist"
+			}
+		}
+		if _, err := exec.LookPath(signingProgram("ssh", "ssh-keygen")); err != nil {
+			return "ssh-keygen is not installed"
+		}
+
+	case "openpgp":
+		if key != "" && (sshKeyLiteral(key) || strings.HasSuffix(key, ".pub")) {
+			return "user.signingkey is an SSH key but gpg.format is not ssh"
+		}
+		program := signingProgram("openpgp", "gpg")
+		if _, err := exec.LookPath(program); err != nil {
+			return program + " is not installed"
+		}
+		// Without a key git signs with the committer's identity
+		id := key
+		if id == "" {
+			ident, _ := gitOutput("git", "var", "GIT_COMMITTER_IDENT")
+			id = identEmail(ident)
+		}
+		if _, err := gitOutput(program, "--batch", "--list-secret-keys", id); err != nil {
+			return "no secret key for " + id + " in " + program
+		}
+
+	case "x509":
+		program := signingProgram("x509", "gpgsm")
+		if _, err := exec.LookPath(program); err != nil {
+			return program + " is not installed"
+		}
+
+	default:
+		return "gpg.format is " + format + ", git knows openpgp, x509 and ssh"
+	}
+
+	return ""
+}
+
+// signingProgram returns the program git runs for a signature format
+func signingProgram(format, fallback string) string {
+	if program, _ := gitOutput("git", "config", "gpg."+format+".program"); strings.TrimSpace(program) != "" {
+		return strings.TrimSpace(program)
+	}
+	// gpg.program is the old name of gpg.openpgp.program
+	if format == "openpgp" {
+		if program, _ := gitOutput("git", "config", "gpg.program"); strings.TrimSpace(program) != "" {
+			return strings.TrimSpace(program)
+		}
+	}
+	return fallback
+}
+
+// allowedSignersFile returns the configured allowed signers file if it exists
+func allowedSignersFile() string {
+	file, err := gitOutput("git", "config", "gpg.ssh.allowedSignersFile")
+	if err != nil || strings.TrimSpace(file) == "" {
+		return ""
+	}
+	file = expandHome(strings.TrimSpace(file))
+	if !fileExists(file) {
+		return ""
+	}
+	return file
+}
+
+// hasSignature checks the raw commit for a signature header
+func hasSignature(hash string) bool {
+	raw, err := gitOutput("git", "cat-file", "commit", hash)
+	if err != nil {
+		return false
+	}
+	header, _, _ := strings.Cut(raw, "\n\n")
+	return strings.Contains(header, "\ngpgsig ") || strings.Contains(header, "\ngpgsig-sha256 ")
+}
+
+// sshKeyLiteral recognizes a public key given inline instead of as a path
+func sshKeyLiteral(key string) bool {
+	return strings.HasPrefix(key, "key::") || strings.HasPrefix(key, "ssh-") ||
+		strings.HasPrefix(key, "ecdsa-") || strings.HasPrefix(key, "sk-")
+}
+
+// expandHome resolves a leading ~/ the way git does for path settings
+func expandHome(p string) string {
+	if strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, p[2:])
+		}
+	}
+	return p
+}
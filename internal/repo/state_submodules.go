@@ -0,0 +1,178 @@
+package repo
+
+import (
+	"path"
+	"strings"
+
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// collectSubmodules builds the health of every submodule, nested ones
+// included, from git submodule status --recursive (R040, R045)
+func collectSubmodules(state *model.RepoState) error {
+	top, err := gitOutput("git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil
+	}
+	top = strings.TrimSpace(top)
+
+	// Paths are relative to the current directory, run from the top
+	output, err := gitOutput("git", "-C", top, "submodule", "status", "--recursive")
+	if err != nil || strings.TrimSpace(output) == "" {
+		return nil
+	}
+
+	staged := stagedGitlinks(top)
+	urls := submoduleURLs(top)
+
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		sub, ok := parseSubmoduleStatus(line)
+		if !ok {
+			continue
+		}
+		dir := path.Join(top, sub.Path)
+
+		if u, ok := urls[sub.Path]; ok {
+			sub.URL, sub.ConfiguredURL = u[0], u[1]
+		}
+
+		switch sub.State {
+		case model.SubmoduleUninitialized, model.SubmoduleConflict:
+			state.Submodules = append(state.Submodules, sub)
+			continue
+		case model.SubmoduleOutOfSync:
+			sub.Recorded = recordedCommit(top, sub.Path)
+			if sub.Recorded != "" {
+				sub.Behind = isAncestor(dir, sub.Commit, sub.Recorded)
+				sub.Ahead = isAncestor(dir, sub.Recorded, sub.Commit)
+			}
+		default:
+			sub.Recorded = sub.Commit
+		}
+
+		if status, err := gitOutput("git", "-C", dir, "status", "--porcelain"); err == nil && strings.TrimSpace(status) != "" {
+			sub.Dirty = len(strings.Split(strings.TrimSpace(status), "\n"))
+		}
+
+		// Detached is normal for submodules, commits only HEAD knows are not
+		if _, err := gitOutput("git", "-C", dir, "symbolic-ref", "-q", "HEAD"); err != nil {
+			contained, _ := gitOutput("git", "-C", dir, "for-each-ref", "--count=1", "--contains", "HEAD", "refs/heads", "refs/remotes")
+			sub.DetachedWork = strings.TrimSpace(contained) == ""
+		}
+
+		// A staged pointer nobody else can fetch
+		if commit, ok := staged[sub.Path]; ok {
+			onRemote, _ := gitOutput("git", "-C", dir, "for-each-ref", "--count=1", "--contains", commit, "refs/remotes")
+			if strings.TrimSpace(onRemote) == "" {
+				sub.StagedUnpushed = commit
+			}
+		}
+
+		state.Submodules = append(state.Submodules, sub)
+	}
+
+	return nil
+}
+
+// parseSubmoduleStatus reads one line of git submodule status:
+// <prefix><hash> <path>[ (<describe>)]
+func parseSubmoduleStatus(line string) (model.Submodule, bool) {
+	if len(line) < 2 {
+		return model.Submodule{}, false
+	}
+
+	var sub model.Submodule
+	switch line[0] {
+	case '-':
+		sub.State = model.SubmoduleUninitialized
+	case '+':
+		sub.State = model.SubmoduleOutOfSync
+	case 'U':
+		sub.State = model.SubmoduleConflict
+	case ' ':
+		sub.State = model.SubmoduleInSync
+	default:
+		return model.Submodule{}, false
+	}
+
+	rest := line[1:]
+	space := strings.Index(rest, " ")
+	if space < 0 {
+		return model.Submodule{}, false
+	}
+	sub.Commit = rest[:space]
+	sub.Path = rest[space+1:]
+	if open := strings.LastIndex(sub.Path, " ("); open >= 0 && strings.HasSuffix(sub.Path, ")") {
+		sub.Path = sub.Path[:open]
+	}
+
+	return sub, sub.Path != ""
+}
+
+// recordedCommit returns the commit the parent repository's index records
+// for a submodule, nested submodules included
+func recordedCommit(top, subPath string) string {
+	// Run from the directory holding the submodule so the parent is the right repo
+	output, err := gitOutput("git", "-C", path.Join(top, path.Dir(subPath)), "ls-files", "-s", "--", path.Base(subPath))
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(output)
+	if len(fields) < 2 || fields[0] != "160000" {
+		return ""
+	}
+	return fields[1]
+}
+
+// stagedGitlinks returns the submodule commits staged in the top repository
+func stagedGitlinks(top string) map[string]string {
+	gitlinks := make(map[string]string)
+
+	output, err := gitOutput("git", "-C", top, "diff", "--cached", "--raw", "-z", "--no-abbrev", "--diff-filter=AM")
+	if err != nil {
+		return gitlinks
+	}
+
+	tokens := strings.Split(output, "\x00")
+	for i := 0; i+1 < len(tokens); i++ {
+		fields := strings.Fields(tokens[i])
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], ":") {
+			continue
+		}
+		if fields[1] == "160000" {
+			gitlinks[tokens[i+1]] = fields[3]
+		}
+		i++
+	}
+	return gitlinks
+}
+
+// submoduleURLs maps each top-level submodule path to its URL in
+// .gitmodules and in .git/config
+func submoduleURLs(top string) map[string][2]string {
+	urls := make(map[string][2]string)
+
+	gitmodules := path.Join(top, ".gitmodules")
+	paths, err := gitOutput("git", "config", "--file", gitmodules, "--get-regexp", `^submodule\..*\.path$`)
+	if err != nil {
+		return urls
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(paths), "\n") {
+		key, subPath, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "submodule."), ".path")
+
+		declared, _ := gitOutput("git", "config", "--file", gitmodules, "submodule."+name+".url")
+		configured, _ := gitOutput("git", "-C", top, "config", "--local", "submodule."+name+".url")
+		urls[subPath] = [2]string{strings.TrimSpace(declared), strings.TrimSpace(configured)}
+	}
+	return urls
+}
+
+// isAncestor checks if commit a is an ancestor of commit b inside a repository
+func isAncestor(dir, a, b string) bool {
+	return runGitCommand("git", "-C", dir, "merge-base", "--is-ancestor", a, b) == nil
+}
@@ -0,0 +1,188 @@
+package repo
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/VectorSophie/git-next/internal/config"
+	"github.com/VectorSophie/git-next/pkg/model"
+)
+
+// collectSyncPrediction simulates syncing HEAD with its upstream and the
+// default branch (R005, R006, R030-R033, R048, R051, R063-R065)
+func collectSyncPrediction(state *model.RepoState, cfg *config.Config) error {
+	if state.OnDetachedHead {
+		return nil
+	}
+
+	// Upstream: only worth simulating when there is something to pull
+	if state.Behind > 0 && !state.NoUpstream {
+		if conflicts, ok := predictConflicts("HEAD", "@{u}"); ok {
+			state.UpstreamMergePredicted = true
+			state.UpstreamConflicts = conflicts
+		}
+	}
+
+	// Default branch: only when it has moved past our fork point
+	if defaultBranch, err := defaultBranchRef(); err == nil {
+		if _, err := gitOutput("git", "merge-base", "--is-ancestor", defaultBranch, "HEAD"); err != nil {
+			if conflicts, ok := predictConflicts("HEAD", defaultBranch); ok {
+				state.DefaultBranch = defaultBranch
+				state.DefaultMergePredicted = true
+				state.DefaultBranchConflicts = conflicts
+			}
+		}
+	}
+
+	detectConflictMinimizingStrategy(state, cfg)
+
+	// R064/R065: Pulling into a dirty tree
+	detectDirtyUpstreamOverlap(state)
+
+	return nil
+}
+
+// detectConflictMinimizingStrategy decides whether merging beats rebasing.
+// A rebase replays every local commit and may stop on each one that touches
+// a conflicting path, a merge resolves the final state once.
+func detectConflictMinimizingStrategy(state *model.RepoState, cfg *config.Config) {
+	minConflicts := cfg.GetIntParam("R063", "min_conflicts", 3)
+	if len(state.UpstreamConflicts) < minConflicts || state.Ahead == 0 {
+		return
+	}
+
+	args := append([]string{"log", "--no-merges", "--format=%H", "@{u}..HEAD", "--"}, state.UpstreamConflicts...)
+	output, err := gitOutput("git", args...)
+	if err != nil {
+		return
+	}
+
+	state.RebaseConflictCommits = len(strings.Fields(output))
+	state.PreferMergeForConflicts = state.RebaseConflictCommits > 1
+}
+
+// detectDirtyUpstreamOverlap intersects uncommitted paths with the paths
+// the incoming commits change. Git refuses to pull over those, autostash
+// handles the rest.
+func detectDirtyUpstreamOverlap(state *model.RepoState) {
+	if !state.Dirty || state.Behind == 0 || state.NoUpstream {
+		return
+	}
+
+	// Three dots: what upstream changed since the merge base, not our own commits
+	output, err := gitOutput("git", "diff", "--name-only", "HEAD...@{u}")
+	if err != nil {
+		return
+	}
+	state.IncomingPaths = uniquePaths(strings.Split(output, "\n"))
+
+	incoming := make(map[string]bool)
+	for _, p := range state.IncomingPaths {
+		incoming[p] = true
+	}
+
+	local := append(append(append(append([]string{}, state.StagedPaths...), state.ModifiedPaths...),
+		state.DeletedPaths...), state.UntrackedPaths...)
+	for _, p := range uniquePaths(local) {
+		if incoming[p] {
+			state.DirtyUpstreamOverlap = append(state.DirtyUpstreamOverlap, p)
+		}
+	}
+}
+
+// predictConflicts returns the paths that would conflict when merging
+// theirs into ours, without touching the index or working tree.
+// ok is false if the merge could not be simulated.
+func predictConflicts(ours, theirs string) (conflicts []string, ok bool) {
+	// git >= 2.38: real merge, exit status 1 means conflicts
+	stdout, code := gitExitCode("merge-tree", "--write-tree", "--name-only", "--no-messages", ours, theirs)
+	switch code {
+	case 0:
+		return nil, true
+	case 1:
+		lines := strings.Split(strings.TrimRight(stdout, "\n"), "\n")
+		// First line is the (partially merged) tree
+		return uniquePaths(lines[1:]), true
+	}
+
+	return predictConflictsTrivial(ours, theirs)
+}
+
+// predictConflictsTrivial uses the pre-2.38 merge-tree, which prints a
+// per-path diff with conflict markers for paths changed on both sides
+func predictConflictsTrivial(ours, theirs string) ([]string, bool) {
+	base, err := gitOutput("git", "merge-base", ours, theirs)
+	if err != nil {
+		return nil, false
+	}
+
+	output, err := gitOutput("git", "merge-tree", strings.TrimSpace(base), ours, theirs)
+	if err != nil {
+		return nil, false
+	}
+
+	// Blocks start with an unindented header ("changed in both"), followed
+	// by indented "base/our/their <mode> <sha> <path>" lines and a diff
+	var conflicts []string
+	path, conflicted := "", false
+	flush := func() {
+		if path != "" && conflicted {
+			conflicts = append(conflicts, path)
+		}
+		path, conflicted = "", false
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "  "):
+			fields := strings.Fields(line)
+			if len(fields) >= 4 && path == "" {
+				path = strings.Join(fields[3:], " ")
+			}
+		case strings.HasPrefix(line, "+<<<<<<<"):
+			conflicted = true
+		case !strings.HasPrefix(line, "@@") && !strings.HasPrefix(line, "+") &&
+			!strings.HasPrefix(line, "-") && !strings.HasPrefix(line, " "):
+			flush()
+		}
+	}
+	flush()
+
+	return uniquePaths(conflicts), true
+}
+
+// gitExitCode runs git and returns stdout with the exit code,
+// -1 if git could not be run
+func gitExitCode(args ...string) (string, int) {
+	cmd := exec.Command("git", args...)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	err := cmd.Run()
+	if err == nil {
+		return stdout.String(), 0
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return stdout.String(), exitErr.ExitCode()
+	}
+	return "", -1
+}
+
+// uniquePaths drops empty lines and duplicates, keeping order
+func uniquePaths(lines []string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, line := range lines {
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		paths = append(paths, line)
+	}
+	return paths
+}
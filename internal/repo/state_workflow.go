@@ -10,30 +10,68 @@ import (
 )
 
 // collectWorkflowHygiene detects workflow hygiene issues (R047-R051)
-func collectWorkflowHygiene(state *model.RepoState, cfg *config.Config) error {
+func collectWorkflowHygiene(state *model.RepoState, cfg *config.Config, s scope) error {
 	// R047: Work on main instead of feature branch
-	if err := detectWorkOnMain(state, cfg); err != nil {
-		return err
+	if s.needs("R047") {
+		if err := detectWorkOnMain(state, cfg); err != nil {
+			return err
+		}
 	}
 
-	// R048: Long-lived feature branch
-	if err := detectLongLivedBranch(state, cfg); err != nil {
-		return err
+	// R048: Long-lived feature branch (R047 yields to it)
+	if s.needs("R047", "R048") {
+		if err := detectLongLivedBranch(state, cfg); err != nil {
+			return err
+		}
 	}
 
 	// R049: Squash recommended
-	if err := detectNoisyCommits(state); err != nil {
-		return err
+	if s.needs("R049") {
+		if err := detectNoisyCommits(state); err != nil {
+			return err
+		}
 	}
 
 	// R050: WIP commit on shared branch
-	if err := detectWIPCommit(state, cfg); err != nil {
-		return err
+	if s.needs("R050") {
+		if err := detectWIPCommit(state, cfg); err != nil {
+			return err
+		}
 	}
 
 	// R051: Rebase instead of merge recommended
-	if err := detectRebaseInsteadOfMerge(state, cfg); err != nil {
-		return err
+	if s.needs("R051") {
+		if err := detectRebaseInsteadOfMerge(state, cfg); err != nil {
+			return err
+		}
+	}
+
+	// R059/R060: Pending fixup!/squash!/amend! commits
+	if s.needs("R059", "R060") {
+		if err := detectPendingFixups(state); err != nil {
+			return err
+		}
+	}
+
+	// R072: Branch name violates the naming policy
+	if s.needs("R072") {
+		if err := detectBranchNaming(state, cfg); err != nil {
+			return err
+		}
+	}
+
+	// R073: Wrong commit identity
+	if s.needs("R073") {
+		if err := detectIdentity(state, cfg); err != nil {
+			return err
+		}
+	}
+
+	// R074: Unsigned or badly signed commits
+	if s.needs("R074") {
+		if err := detectSigning(state, cfg); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -75,14 +113,14 @@ func detectLongLivedBranch(state *model.RepoState, cfg *config.Config) error {
 		return nil
 	}
 
-	// Get merge-base with main
-	mergeBase, err := gitOutput("git", "merge-base", "HEAD", "origin/main")
+	// Get merge-base with the default branch
+	defaultBranch, err := defaultBranchRef()
 	if err != nil {
-		// Try master
-		mergeBase, err = gitOutput("git", "merge-base", "HEAD", "origin/master")
-		if err != nil {
-			return nil
-		}
+		return nil
+	}
+	mergeBase, err := gitOutput("git", "merge-base", "HEAD", defaultBranch)
+	if err != nil {
+		return nil
 	}
 
 	if strings.TrimSpace(mergeBase) == "" {
@@ -133,18 +171,13 @@ func detectNoisyCommits(state *model.RepoState) error {
 	lines := strings.Split(strings.TrimSpace(commits), "\n")
 	noisyCount := 0
 
-	noisyPatterns := []string{
-		"fix", "oops", "wip", "temp", "debug", "test",
-		"typo", ".", "update", "change",
-	}
-
 	for _, line := range lines {
-		lineLower := strings.ToLower(strings.TrimSpace(line))
-		for _, pattern := range noisyPatterns {
-			if lineLower == pattern || strings.HasPrefix(lineLower, pattern+" ") {
-				noisyCount++
-				break
-			}
+		// fixup!/squash! commits are intentional, R059 handles them
+		if _, _, ok := parseAutosquashSubject(line); ok {
+			continue
+		}
+		if IsNoisyCommitMessage(line) {
+			noisyCount++
 		}
 	}
 
@@ -157,6 +190,23 @@ func detectNoisyCommits(state *model.RepoState) error {
 	return nil
 }
 
+// noisyPatterns are commit subjects that carry no information on their own
+var noisyPatterns = []string{
+	"fix", "oops", "wip", "temp", "debug", "test",
+	"typo", ".", "update", "change",
+}
+
+// IsNoisyCommitMessage reports whether a commit subject is noise worth squashing
+func IsNoisyCommitMessage(subject string) bool {
+	lower := strings.ToLower(strings.TrimSpace(subject))
+	for _, pattern := range noisyPatterns {
+		if lower == pattern || strings.HasPrefix(lower, pattern+" ") {
+			return true
+		}
+	}
+	return false
+}
+
 // detectWIPCommit checks for WIP commits on shared branches
 func detectWIPCommit(state *model.RepoState, cfg *config.Config) error {
 	if !state.OnProtectedBranch {
@@ -205,3 +255,104 @@ func detectRebaseInsteadOfMerge(state *model.RepoState, cfg *config.Config) erro
 
 	return nil
 }
+
+// autosquashPrefixes are the subject prefixes written by git commit --fixup/--squash
+var autosquashPrefixes = map[string]string{
+	"fixup! ":  "fixup",
+	"squash! ": "squash",
+	"amend! ":  "amend",
+}
+
+// parseAutosquashSubject returns the kind and target of a fixup!/squash!/amend! subject.
+// Nested prefixes ("fixup! fixup! X") all point at X.
+func parseAutosquashSubject(subject string) (kind string, target string, ok bool) {
+	target = strings.TrimSpace(subject)
+	for {
+		matched := false
+		for prefix, k := range autosquashPrefixes {
+			if strings.HasPrefix(target, prefix) {
+				if kind == "" {
+					kind = k
+				}
+				target = strings.TrimPrefix(target, prefix)
+				matched = true
+			}
+		}
+		if !matched {
+			break
+		}
+	}
+	return kind, target, kind != ""
+}
+
+// detectPendingFixups finds fixup!/squash!/amend! commits in the unpushed range
+// and matches them to their target commits
+func detectPendingFixups(state *model.RepoState) error {
+	unpushed, err := gitOutput("git", "log", "--format=%H%x00%s", "HEAD", "--not", "--remotes")
+	if err != nil || strings.TrimSpace(unpushed) == "" {
+		return nil
+	}
+
+	// Targets can be anywhere in recent history, including published commits
+	history, err := gitOutput("git", "log", "--format=%H%x00%s", "-n", "500", "HEAD")
+	if err != nil {
+		return nil
+	}
+
+	type commit struct{ hash, subject string }
+	var recent []commit
+	for _, line := range strings.Split(strings.TrimSpace(history), "\n") {
+		if parts := strings.SplitN(line, "\x00", 2); len(parts) == 2 {
+			recent = append(recent, commit{parts[0], parts[1]})
+		}
+	}
+
+	local := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(unpushed), "\n") {
+		if parts := strings.SplitN(line, "\x00", 2); len(parts) == 2 {
+			local[parts[0]] = true
+		}
+	}
+
+	oldestTarget := -1
+	for i, c := range recent {
+		if !local[c.hash] {
+			continue
+		}
+		kind, target, ok := parseAutosquashSubject(c.subject)
+		if !ok {
+			continue
+		}
+
+		fixup := model.FixupCommit{Hash: c.hash, Kind: kind, Subject: c.subject}
+
+		// Like git, match a hash prefix first, then the subject; only older commits qualify
+		for j := i + 1; j < len(recent); j++ {
+			if len(target) >= 4 && strings.HasPrefix(recent[j].hash, target) ||
+				recent[j].subject == target {
+				fixup.TargetHash = recent[j].hash
+				fixup.TargetPublished = !local[recent[j].hash]
+				if j > oldestTarget {
+					oldestTarget = j
+				}
+				break
+			}
+		}
+
+		if fixup.TargetPublished {
+			state.FixupTargetPublished = true
+		}
+		state.PendingFixups = append(state.PendingFixups, fixup)
+	}
+
+	if oldestTarget >= 0 {
+		base := recent[oldestTarget].hash
+		if _, err := gitOutput("git", "rev-parse", "--verify", "--quiet", base+"^"); err != nil {
+			state.AutosquashBase = "--root"
+		} else {
+			state.AutosquashBase = base[:7] + "^"
+		}
+	}
+
+	return nil
+}
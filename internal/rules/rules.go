@@ -1,6 +1,9 @@
 package rules
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/VectorSophie/git-next/internal/config"
 	"github.com/VectorSophie/git-next/pkg/model"
 )
@@ -8,6 +11,9 @@ import (
 // Rule represents a function that evaluates repository state
 type Rule func(state model.RepoState) bool
 
+// EvidenceFunc explains why a rule fired, one line per fact
+type EvidenceFunc func(state model.RepoState) []string
+
 // RuleDef defines a rule with its metadata
 type RuleDef struct {
 	ID          string
@@ -15,6 +21,7 @@ type RuleDef struct {
 	Command     string
 	Description string
 	Priority    int
+	Evidence    EvidenceFunc
 }
 
 // AllRules returns all defined rules sorted by priority
@@ -44,3 +51,86 @@ func AllRules(cfg *config.Config) []RuleDef {
 
 	return all
 }
+
+// RewriteIsSafe reports whether the newest n commits can be rewritten
+// without touching a commit that a remote already has
+func RewriteIsSafe(state model.RepoState, n int) bool {
+	return n <= state.UnpublishedCount
+}
+
+// oldestPublishedInRange returns the oldest published commit among the newest n
+func oldestPublishedInRange(state model.RepoState, n int) (model.CommitPublication, bool) {
+	var oldest model.CommitPublication
+	found := false
+	for i := 0; i < n && i < len(state.PublicationMap); i++ {
+		if state.PublicationMap[i].Published() {
+			oldest = state.PublicationMap[i]
+			found = true
+		}
+	}
+	return oldest, found
+}
+
+// rewritePlan guards a command that rewrites the newest n commits: the
+// first line is "fix: <cmd>" when no remote has any of them and none is a
+// merge, otherwise why they are left alone. Local refs that keep the old
+// commits are noted.
+func rewritePlan(state model.RepoState, n int, cmd string) []string {
+	if published, ok := oldestPublishedInRange(state, n); ok || !RewriteIsSafe(state, n) {
+		if ok {
+			return []string{fmt.Sprintf("%s is already on %s: rewriting needs a force-push, leave it",
+				published.Hash[:7], strings.Join(published.RemoteBranches, ", "))}
+		}
+		return []string{"commits are already on a remote: rewriting needs a force-push, leave them"}
+	}
+	for i := 0; i < n && i < len(state.PublicationMap); i++ {
+		if c := state.PublicationMap[i]; c.Merge {
+			return []string{fmt.Sprintf("%s is a merge: a rebase would flatten it, fix the commits by hand", c.Hash[:7])}
+		}
+	}
+
+	plan := []string{"fix: " + cmd}
+	for i := 0; i < n && i < len(state.PublicationMap); i++ {
+		if c := state.PublicationMap[i]; c.SharedLocally() {
+			plan = append(plan, fmt.Sprintf("%s is also on %s, which keeps the old commits", c.Hash[:7],
+				strings.Join(append(append(append([]string{}, c.LocalBranches...), c.Tags...), c.Stashes...), ", ")))
+			break
+		}
+	}
+	return plan
+}
+
+// maxEvidencePaths caps how many paths an evidence line lists
+const maxEvidencePaths = 5
+
+// upstreamSyncEvidence reports the simulated merge with the upstream
+func upstreamSyncEvidence(state model.RepoState) []string {
+	if !state.UpstreamMergePredicted {
+		return nil
+	}
+	return []string{predictionLine("upstream", state.UpstreamConflicts)}
+}
+
+// defaultSyncEvidence reports the simulated merge with the default branch
+func defaultSyncEvidence(state model.RepoState) []string {
+	if !state.DefaultMergePredicted {
+		return nil
+	}
+	return []string{predictionLine(state.DefaultBranch, state.DefaultBranchConflicts)}
+}
+
+// predictionLine formats the outcome of a simulated merge
+func predictionLine(target string, conflicts []string) string {
+	if len(conflicts) == 0 {
+		return fmt.Sprintf("Simulated merge with %s: clean", target)
+	}
+
+	shown := conflicts
+	more := ""
+	if len(shown) > maxEvidencePaths {
+		shown = shown[:maxEvidencePaths]
+		more = fmt.Sprintf(" and %d more", len(conflicts)-maxEvidencePaths)
+	}
+	return fmt.Sprintf("Simulated merge with %s: %d file(s) will conflict: %s%s",
+		target, len(conflicts), strings.Join(shown, ", "), more)
+}
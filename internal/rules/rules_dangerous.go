@@ -1,6 +1,9 @@
 package rules
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/VectorSophie/git-next/internal/config"
 	"github.com/VectorSophie/git-next/pkg/model"
 )
@@ -32,9 +35,10 @@ func DangerousRules(cfg *config.Config) []RuleDef {
 		{
 			ID:          "R040",
 			Check:       R040,
-			Command:     "git submodule update --remote",
+			Command:     "git submodule update --recursive",
 			Description: "Submodule pointer rewrite without update - builds will fail creatively",
 			Priority:    100,
+			Evidence:    R040Evidence,
 		},
 		{
 			ID:          "R041",
@@ -42,13 +46,15 @@ func DangerousRules(cfg *config.Config) []RuleDef {
 			Command:     "# Accidental history rewrite detected - you don't get to pretend this was fine",
 			Description: "Rebase or filter-branch after commits pulled by others",
 			Priority:    100,
+			Evidence:    R041Evidence,
 		},
 		{
 			ID:          "R021",
 			Check:       R021,
 			Command:     "git revert HEAD",
-			Description: "Last commit was pushed - use revert instead of reset",
+			Description: "Commits already pushed - use revert instead of reset",
 			Priority:    100,
+			Evidence:    R021Evidence,
 		},
 		{
 			ID:          "R009",
@@ -64,12 +70,37 @@ func DangerousRules(cfg *config.Config) []RuleDef {
 			Description: "Rebase in progress - complete or abort",
 			Priority:    97,
 		},
+		{
+			ID:          "R068",
+			Check:       R068,
+			Command:     "git am --continue OR git am --skip OR git am --abort",
+			Description: "Applying patches with git am - complete, skip the patch or abort",
+			Priority:    97,
+			Evidence:    R068Evidence,
+		},
 		{
 			ID:          "R011",
 			Check:       R011,
 			Command:     "git cherry-pick --continue OR git cherry-pick --abort",
 			Description: "Cherry-pick in progress - complete or abort",
 			Priority:    96,
+			Evidence:    sequencerEvidence,
+		},
+		{
+			ID:          "R067",
+			Check:       R067,
+			Command:     "git revert --continue OR git revert --skip OR git revert --abort",
+			Description: "Revert in progress - complete, skip the commit or abort",
+			Priority:    96,
+			Evidence:    sequencerEvidence,
+		},
+		{
+			ID:          "R069",
+			Check:       R069,
+			Command:     "git bisect good OR git bisect bad OR git bisect reset",
+			Description: "Bisect in progress - mark this commit, or reset to end the search",
+			Priority:    96,
+			Evidence:    R069Evidence,
 		},
 		{
 			ID:          "R001",
@@ -84,6 +115,7 @@ func DangerousRules(cfg *config.Config) []RuleDef {
 			Command:     "git merge origin/<branch>",
 			Description: "Diverged on protected branch - merge instead of rebase",
 			Priority:    90,
+			Evidence:    upstreamSyncEvidence,
 		},
 	}
 }
@@ -105,9 +137,31 @@ func R039(state model.RepoState) bool {
 	return state.ResetOnProtectedBranch
 }
 
-// R040 - Submodule pointer rewrite without update
+// R040 - Submodule pointer rewrite without update: the checked-out
+// commit is behind the recorded one, or a staged pointer is on no remote
 func R040(state model.RepoState) bool {
-	return state.SubmoduleRewriteNoUpdate
+	for _, s := range state.Submodules {
+		if s.Behind || s.StagedUnpushed != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// R040Evidence names each submodule with the command that fixes it
+func R040Evidence(state model.RepoState) []string {
+	var evidence []string
+	for _, s := range state.Submodules {
+		if s.Behind {
+			evidence = append(evidence, fmt.Sprintf("%s: checked out %s is behind the recorded %s, committing now rewinds it -> git submodule update --recursive -- %s",
+				s.Path, s.Commit[:7], s.Recorded[:7], s.Path))
+		}
+		if s.StagedUnpushed != "" {
+			evidence = append(evidence, fmt.Sprintf("%s: staged pointer %s is on no remote branch -> git -C %s push",
+				s.Path, s.StagedUnpushed[:7], s.Path))
+		}
+	}
+	return evidence
 }
 
 // R041 - Accidental history rewrite
@@ -116,8 +170,32 @@ func R041(state model.RepoState) bool {
 }
 
 // R021 - Revert Public Commit (beats reset)
+// Fires when HEAD, or any commit in the local range a reset would rewrite, is published
 func R021(state model.RepoState) bool {
-	return state.LastCommitPushed
+	return state.LastCommitPushed ||
+		!RewriteIsSafe(state, state.CommitCountSincePush)
+}
+
+// R021Evidence names the oldest published commit a rewrite would touch
+func R021Evidence(state model.RepoState) []string {
+	n := state.CommitCountSincePush
+	if n < 1 {
+		n = 1
+	}
+	oldest, ok := oldestPublishedInRange(state, n)
+	if !ok {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s %s is on %s", oldest.Hash[:7], oldest.Subject,
+		strings.Join(oldest.RemoteBranches, ", "))}
+}
+
+// R041Evidence names the published commit that is no longer in HEAD
+func R041Evidence(state model.RepoState) []string {
+	if state.RewrittenPublishedCommit == "" {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s is on a remote but no longer in HEAD", state.RewrittenPublishedCommit[:7])}
 }
 
 // R009 - Merge in Progress
@@ -132,7 +210,42 @@ func R010(state model.RepoState) bool {
 
 // R011 - Cherry-pick in Progress
 func R011(state model.RepoState) bool {
-	return state.CherryPickInProgress
+	return state.CherryPickInProgress ||
+		(state.SequencerInProgress && state.SequencerOperation == "cherry-pick" && !state.RevertInProgress)
+}
+
+// R067 - Revert in Progress
+func R067(state model.RepoState) bool {
+	return state.RevertInProgress ||
+		(state.SequencerInProgress && state.SequencerOperation == "revert" && !state.CherryPickInProgress)
+}
+
+// sequencerEvidence reports how many commits a multi-commit cherry-pick or revert has left
+func sequencerEvidence(state model.RepoState) []string {
+	if !state.SequencerInProgress {
+		return nil
+	}
+	return []string{fmt.Sprintf("%d commit(s) left in .git/sequencer/todo", state.SequencerRemaining)}
+}
+
+// R068 - git am in Progress
+func R068(state model.RepoState) bool {
+	return state.AmInProgress
+}
+
+// R068Evidence explains why this isn't reported as a rebase
+func R068Evidence(state model.RepoState) []string {
+	return []string{".git/rebase-apply/applying exists: this is git am, not a rebase"}
+}
+
+// R069 - Bisect in Progress
+func R069(state model.RepoState) bool {
+	return state.BisectInProgress
+}
+
+// R069Evidence explains the detached HEAD
+func R069Evidence(state model.RepoState) []string {
+	return []string{"HEAD is detached on purpose while bisecting, don't check out a branch"}
 }
 
 // R001 - Detached HEAD
@@ -144,5 +257,6 @@ func R001(state model.RepoState) bool {
 func R032(state model.RepoState) bool {
 	return state.Ahead > 0 &&
 		state.Behind > 0 &&
-		state.OnProtectedBranch
+		state.OnProtectedBranch &&
+		!state.UpstreamRewritten
 }
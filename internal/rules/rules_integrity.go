@@ -1,18 +1,54 @@
 package rules
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/VectorSophie/git-next/pkg/model"
 )
 
 // IntegrityRules returns rules for priority 89-60: Repo integrity issues
 func IntegrityRules() []RuleDef {
 	return []RuleDef{
+		{
+			ID:          "R070",
+			Check:       R070,
+			Command:     "rm <lock-files>",
+			Description: "Stale lock file left by a crashed git process - every command that needs it will fail",
+			Priority:    89,
+			Evidence:    R070Evidence,
+		},
+		{
+			ID:          "R071",
+			Check:       R071,
+			Command:     "git restore --staged <files>",
+			Description: "Secrets in staged or unpushed changes - a pushed token is leaked, no matter how fast you revert",
+			Priority:    89,
+			Evidence:    R071Evidence,
+		},
+		{
+			ID:          "R062",
+			Check:       R062,
+			Command:     "git rebase --onto @{u} <fork-point> OR git pull --rebase",
+			Description: "Upstream was force-pushed - plain rebase or merge would duplicate rewritten commits",
+			Priority:    88,
+			Evidence:    R062Evidence,
+		},
+		{
+			ID:          "R066",
+			Check:       R066,
+			Command:     "git branch rescue/<sha> <sha>",
+			Description: "Commits left behind - no branch, tag or stash points at them anymore",
+			Priority:    87,
+			Evidence:    R066Evidence,
+		},
 		{
 			ID:          "R042",
 			Check:       R042,
 			Command:     "# Remove conflict markers from files before committing",
 			Description: "Conflicted files staged - if <<<<<<< is in the diff, stop pretending",
 			Priority:    89,
+			Evidence:    R042Evidence,
 		},
 		{
 			ID:          "R043",
@@ -20,20 +56,23 @@ func IntegrityRules() []RuleDef {
 			Command:     "git lfs track <pattern> && git add .gitattributes",
 			Description: "Binary files changed without LFS - Git is not a landfill",
 			Priority:    85,
+			Evidence:    R043Evidence,
 		},
 		{
 			ID:          "R044",
 			Check:       R044,
-			Command:     "git config core.autocrlf true (or false)",
+			Command:     "git add --renormalize .",
 			Description: "Line ending normalization conflict - someone's editor declared war",
 			Priority:    82,
+			Evidence:    R044Evidence,
 		},
 		{
 			ID:          "R045",
 			Check:       R045,
-			Command:     "cd <submodule> && git checkout <branch>",
-			Description: "Submodule detached HEAD - time capsule mode engaged",
+			Command:     "# Run the command on each submodule line - every problem has its own fix",
+			Description: "Submodule uninitialized, conflicted, dirty or drifted - time capsule mode engaged",
 			Priority:    81,
+			Evidence:    R045Evidence,
 		},
 		{
 			ID:          "R046",
@@ -48,6 +87,7 @@ func IntegrityRules() []RuleDef {
 			Command:     "git rebase origin/<branch> OR git merge origin/<branch>",
 			Description: "Branch has diverged - need to sync",
 			Priority:    80,
+			Evidence:    upstreamSyncEvidence,
 		},
 		{
 			ID:          "R034",
@@ -56,12 +96,21 @@ func IntegrityRules() []RuleDef {
 			Description: "No upstream configured for current branch",
 			Priority:    75,
 		},
+		{
+			ID:          "R063",
+			Check:       R063,
+			Command:     "git merge origin/<branch>",
+			Description: "Diverged with many predicted conflicts - merge once instead of rebasing commit by commit",
+			Priority:    71,
+			Evidence:    R063Evidence,
+		},
 		{
 			ID:          "R031",
 			Check:       R031,
 			Command:     "git rebase origin/<branch>",
 			Description: "Feature branch diverged - rebase to keep linear history",
 			Priority:    70,
+			Evidence:    upstreamSyncEvidence,
 		},
 		{
 			ID:          "R035",
@@ -70,6 +119,14 @@ func IntegrityRules() []RuleDef {
 			Description: "Merged branches ready for cleanup",
 			Priority:    65,
 		},
+		{
+			ID:          "R061",
+			Check:       R061,
+			Command:     "git branch -D <branch>",
+			Description: "Squash- or rebase-merged branches ready for cleanup (-d will refuse them)",
+			Priority:    64,
+			Evidence:    R061Evidence,
+		},
 		{
 			ID:          "R036",
 			Check:       R036,
@@ -83,6 +140,7 @@ func IntegrityRules() []RuleDef {
 			Command:     "git merge origin/<branch>",
 			Description: "Existing merge commits detected - continue with merge",
 			Priority:    60,
+			Evidence:    upstreamSyncEvidence,
 		},
 	}
 }
@@ -94,19 +152,133 @@ func R042(state model.RepoState) bool {
 	return state.ConflictedFilesStaged
 }
 
+// R042Evidence lists the staged conflict blocks by line range
+func R042Evidence(state model.RepoState) []string {
+	var evidence []string
+	for i, m := range state.ConflictMarkers {
+		if i == maxEvidencePaths {
+			evidence = append(evidence, fmt.Sprintf("... and %d more", len(state.ConflictMarkers)-i))
+			break
+		}
+		evidence = append(evidence, m)
+	}
+	return evidence
+}
+
 // R043 - Large binaries without LFS
 func R043(state model.RepoState) bool {
 	return state.LargeBinariesWithoutLFS
 }
 
+// R043Evidence lists the offending files and how to get them into LFS
+func R043Evidence(state model.RepoState) []string {
+	var evidence []string
+	add := func(format string, files []string) {
+		for i, f := range files {
+			if i == maxEvidencePaths {
+				evidence = append(evidence, fmt.Sprintf("... and %d more", len(files)-i))
+				break
+			}
+			evidence = append(evidence, fmt.Sprintf(format, f))
+		}
+	}
+	add("%s: large binary outside LFS", state.LargeBinaryFiles)
+	add("%s: tracked by LFS but committed as content, not a pointer", state.LFSSmudgedFiles)
+	add("%s: LFS pointer committed, git-lfs is not installed", state.LFSPointersWithoutLFS)
+
+	if len(state.LFSTrackPatterns) > 0 {
+		// Quoted, or the shell expands the glob when the line is copied
+		quoted := make([]string, len(state.LFSTrackPatterns))
+		for i, p := range state.LFSTrackPatterns {
+			quoted[i] = shellQuote(p)
+		}
+		evidence = append(evidence, "track with: git lfs track "+strings.Join(quoted, " "))
+	}
+	if len(state.LFSSmudgedFiles) > 0 || len(state.LargeBinaryFiles) > 0 {
+		evidence = append(evidence, "then re-stage: git rm --cached <file> && git add <file>")
+	}
+	for _, f := range append(append([]string{}, state.LargeBinaryFiles...), state.LFSSmudgedFiles...) {
+		if strings.HasSuffix(f, "(unpushed commit)") {
+			evidence = append(evidence, "already committed: git lfs migrate import --include=<pattern> before pushing")
+			break
+		}
+	}
+	if !state.LFSInstalled {
+		evidence = append(evidence, "git-lfs is not installed: https://git-lfs.com, then git lfs install")
+	}
+	return evidence
+}
+
 // R044 - Line ending normalization conflict
 func R044(state model.RepoState) bool {
 	return state.LineEndingConflict
 }
 
-// R045 - Submodule detached HEAD
+// R044Evidence lists the affected files and the concrete fix
+func R044Evidence(state model.RepoState) []string {
+	var evidence []string
+	for i, issue := range state.LineEndingIssues {
+		if i == maxEvidencePaths {
+			evidence = append(evidence, fmt.Sprintf("... and %d more (git ls-files --eol)", len(state.LineEndingIssues)-i))
+			break
+		}
+		evidence = append(evidence, fmt.Sprintf("%s: %s", issue.Path, issue.Problem))
+	}
+	if state.LineEndingConfig != "" {
+		evidence = append(evidence, state.LineEndingConfig)
+	}
+	if len(state.LineEndingAttributes) > 0 {
+		evidence = append(evidence, "first add to .gitattributes: "+strings.Join(state.LineEndingAttributes, ", "))
+	}
+	return append(evidence, "then commit the result of git add --renormalize .")
+}
+
+// R045 - Submodule health
 func R045(state model.RepoState) bool {
-	return state.SubmoduleDetachedHead
+	return len(submoduleIssues(state)) > 0
+}
+
+// R045Evidence names each submodule problem with the command that fixes it
+func R045Evidence(state model.RepoState) []string {
+	issues := submoduleIssues(state)
+	if len(issues) > maxEvidencePaths*2 {
+		more := len(issues) - maxEvidencePaths*2
+		issues = append(issues[:maxEvidencePaths*2], fmt.Sprintf("... and %d more (git submodule status --recursive)", more))
+	}
+	return issues
+}
+
+// submoduleIssues describes what is wrong with each submodule, R040's
+// pointer problems excluded
+func submoduleIssues(state model.RepoState) []string {
+	var issues []string
+	for _, s := range state.Submodules {
+		switch {
+		case s.State == model.SubmoduleUninitialized:
+			issues = append(issues, fmt.Sprintf("%s: not initialized -> git submodule update --init --recursive -- %s", s.Path, s.Path))
+			continue
+		case s.State == model.SubmoduleConflict:
+			issues = append(issues, fmt.Sprintf("%s: merge conflict on the pointer -> git -C %s checkout <commit> && git add %s", s.Path, s.Path, s.Path))
+			continue
+		case s.Ahead:
+			issues = append(issues, fmt.Sprintf("%s: checked out %s is newer than the recorded %s -> git add %s",
+				s.Path, s.Commit[:7], s.Recorded[:7], s.Path))
+		case s.State == model.SubmoduleOutOfSync && !s.Behind && s.Recorded != "":
+			issues = append(issues, fmt.Sprintf("%s: checked out %s and recorded %s diverged -> git -C %s log --oneline %s...%s",
+				s.Path, s.Commit[:7], s.Recorded[:7], s.Path, s.Commit[:7], s.Recorded[:7]))
+		}
+		if s.Dirty > 0 {
+			issues = append(issues, fmt.Sprintf("%s: %d uncommitted change(s) -> git -C %s status", s.Path, s.Dirty, s.Path))
+		}
+		if s.DetachedWork {
+			issues = append(issues, fmt.Sprintf("%s: detached HEAD with commits on no branch -> git -C %s switch -c <branch>", s.Path, s.Path))
+		}
+		if s.URLDrift() {
+			issues = append(issues, fmt.Sprintf("%s: .gitmodules says %s, .git/config says %s -> git submodule sync -- %s",
+				s.Path, s.URL, s.ConfiguredURL, s.Path))
+		}
+	}
+	return issues
 }
 
 // R046 - Shallow clone doing history ops
@@ -114,9 +286,93 @@ func R046(state model.RepoState) bool {
 	return state.ShallowCloneHistoryOps
 }
 
+// R062 - Upstream rewritten under us
+func R062(state model.RepoState) bool {
+	return state.UpstreamRewritten
+}
+
+// R062Evidence explains the forced update and where our work starts
+func R062Evidence(state model.RepoState) []string {
+	return []string{
+		state.UpstreamRewriteNote,
+		"Fork point: " + state.UpstreamForkPoint + " (your commits start after it)",
+	}
+}
+
+// R066 - Lost commits
+func R066(state model.RepoState) bool {
+	return len(state.LostCommits) > 0
+}
+
+// R066Evidence lists each lost tip with the command that brings it back
+func R066Evidence(state model.RepoState) []string {
+	var evidence []string
+	for i, l := range state.LostCommits {
+		if i == maxEvidencePaths {
+			evidence = append(evidence, fmt.Sprintf("... and %d more (git-next rescue)", len(state.LostCommits)-i))
+			break
+		}
+		short := l.Hash[:7]
+		evidence = append(evidence, fmt.Sprintf("%s %s (%s, %d commit(s), %s) -> git branch rescue/%s %s",
+			short, l.Subject, l.Age(), l.Commits, l.Source, short, short))
+	}
+	return evidence
+}
+
+// R070 - Stale lock files
+func R070(state model.RepoState) bool {
+	return len(state.StaleLocks) > 0
+}
+
+// R070Evidence lists each lock with its age
+func R070Evidence(state model.RepoState) []string {
+	var evidence []string
+	for i, l := range state.StaleLocks {
+		if i == maxEvidencePaths {
+			evidence = append(evidence, fmt.Sprintf("... and %d more", len(state.StaleLocks)-i))
+			break
+		}
+		evidence = append(evidence, fmt.Sprintf(".git/%s (taken %s)", l.Name, l.Age()))
+	}
+	if state.LockOwnerChecked {
+		return append(evidence, "no git process is running in this repository")
+	}
+	return append(evidence, "could not check for running git processes, make sure none is before removing")
+}
+
+// R071 - Secrets in staged or unpushed changes
+func R071(state model.RepoState) bool {
+	return len(state.SecretFindings) > 0
+}
+
+// R071Evidence lists each finding as file:line with the secret masked
+func R071Evidence(state model.RepoState) []string {
+	var evidence []string
+	committed := false
+	for i, f := range state.SecretFindings {
+		committed = committed || f.Committed
+		if i >= maxEvidencePaths {
+			continue
+		}
+		where := ""
+		if f.Committed {
+			where = ", unpushed commit"
+		}
+		evidence = append(evidence, fmt.Sprintf("%s: %s %s%s", f.Location(), f.Kind, f.Masked(), where))
+	}
+	if len(state.SecretFindings) > maxEvidencePaths {
+		evidence = append(evidence, fmt.Sprintf("... and %d more", len(state.SecretFindings)-maxEvidencePaths))
+	}
+	if committed {
+		evidence = append(evidence, "already committed: rewrite the commits before pushing (git rebase -i)")
+	}
+	return append(evidence, "false positive? add git-next:allow to the line")
+}
+
 // R006 - Diverged Branch
 func R006(state model.RepoState) bool {
-	return state.Ahead > 0 && state.Behind > 0
+	return state.Ahead > 0 && state.Behind > 0 &&
+		!state.UpstreamRewritten
 }
 
 // R034 - No Upstream Configured
@@ -128,7 +384,25 @@ func R034(state model.RepoState) bool {
 func R031(state model.RepoState) bool {
 	return state.Ahead > 0 &&
 		state.Behind > 0 &&
-		!state.OnProtectedBranch
+		!state.OnProtectedBranch &&
+		!state.UpstreamRewritten &&
+		!state.PreferMergeForConflicts
+}
+
+// R063 - Merge to minimize predicted conflicts
+func R063(state model.RepoState) bool {
+	return state.Ahead > 0 &&
+		state.Behind > 0 &&
+		!state.OnProtectedBranch &&
+		!state.UpstreamRewritten &&
+		len(state.DirtyUpstreamOverlap) == 0 &&
+		state.PreferMergeForConflicts
+}
+
+// R063Evidence compares resolving once against resolving per commit
+func R063Evidence(state model.RepoState) []string {
+	return append(upstreamSyncEvidence(state),
+		fmt.Sprintf("A rebase would replay %d of your commits that touch those files, possibly stopping on each", state.RebaseConflictCommits))
 }
 
 // R035 - Merged Branches Ready for Cleanup
@@ -136,6 +410,16 @@ func R035(state model.RepoState) bool {
 	return len(state.MergedBranches) > 0
 }
 
+// R061 - Squash- or rebase-merged branches
+func R061(state model.RepoState) bool {
+	return len(state.SquashMergedBranches) > 0
+}
+
+// R061Evidence lists the branches whose changes already landed upstream
+func R061Evidence(state model.RepoState) []string {
+	return []string{"Branches: " + strings.Join(state.SquashMergedBranches, ", ")}
+}
+
 // R036 - Gone Remote Branches
 func R036(state model.RepoState) bool {
 	return len(state.GoneBranches) > 0
@@ -144,5 +428,11 @@ func R036(state model.RepoState) bool {
 // R033 - Existing Merge History
 func R033(state model.RepoState) bool {
 	return state.HasMergeCommits &&
-		state.Behind > 0
+		state.Behind > 0 &&
+		!state.UpstreamRewritten
+}
+
+// shellQuote quotes a string for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
@@ -1,6 +1,9 @@
 package rules
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/VectorSophie/git-next/internal/config"
 	"github.com/VectorSophie/git-next/pkg/model"
 )
@@ -15,12 +18,29 @@ func WorkflowRules(cfg *config.Config) []RuleDef {
 			Description: "Work on main instead of feature branch - you skipped the whole process part",
 			Priority:    58,
 		},
+		{
+			ID:          "R060",
+			Check:       R060,
+			Command:     "# Do not autosquash: fixup targets are already on a remote",
+			Description: "Fixup commits target published history - squashing would rewrite shared commits",
+			Priority:    57,
+			Evidence:    fixupEvidence,
+		},
 		{
 			ID:          "R048",
 			Check:       R048,
 			Command:     "git merge main (or rebase)",
 			Description: "Long-lived feature branch - merge debt accumulating interest",
 			Priority:    56,
+			Evidence:    defaultSyncEvidence,
+		},
+		{
+			ID:          "R065",
+			Check:       R065,
+			Command:     "git add <files> && git commit",
+			Description: "Incoming changes touch files you modified - commit them before pulling",
+			Priority:    56,
+			Evidence:    dirtyPullEvidence,
 		},
 		{
 			ID:          "R005",
@@ -28,6 +48,23 @@ func WorkflowRules(cfg *config.Config) []RuleDef {
 			Command:     "git pull",
 			Description: "Behind remote and clean - pull updates",
 			Priority:    55,
+			Evidence:    upstreamSyncEvidence,
+		},
+		{
+			ID:          "R064",
+			Check:       R064,
+			Command:     "git pull --autostash OR git stash && git pull && git stash pop",
+			Description: "Behind remote with unrelated local changes - pull with autostash",
+			Priority:    54,
+			Evidence:    dirtyPullEvidence,
+		},
+		{
+			ID:          "R059",
+			Check:       R059,
+			Command:     "git rebase -i --autosquash <base>",
+			Description: "Pending fixup!/squash! commits - autosquash them before pushing",
+			Priority:    53,
+			Evidence:    fixupEvidence,
 		},
 		{
 			ID:          "R049",
@@ -49,6 +86,7 @@ func WorkflowRules(cfg *config.Config) []RuleDef {
 			Command:     "git rebase main",
 			Description: "Rebase recommended instead of merge - keep linear history",
 			Priority:    50,
+			Evidence:    defaultSyncEvidence,
 		},
 		{
 			ID:          "R004",
@@ -57,12 +95,37 @@ func WorkflowRules(cfg *config.Config) []RuleDef {
 			Description: "Local commits ready to push",
 			Priority:    50,
 		},
+		{
+			ID:          "R073",
+			Check:       R073,
+			Command:     "git commit --amend --no-edit --reset-author",
+			Description: "Commits with the wrong email - fix them before they land in shared history",
+			Priority:    53,
+			Evidence:    R073Evidence,
+		},
+		{
+			ID:          "R074",
+			Check:       R074,
+			Command:     "git commit --amend --no-edit -S",
+			Description: "Unsigned commits - the protected branch will reject the push",
+			Priority:    52,
+			Evidence:    R074Evidence,
+		},
+		{
+			ID:          "R072",
+			Check:       R072,
+			Command:     "git branch -m <suggested-name>",
+			Description: "Branch name violates the naming policy - CI will reject it after the push",
+			Priority:    49,
+			Evidence:    R072Evidence,
+		},
 		{
 			ID:          "R030",
 			Check:       R030,
 			Command:     "git pull --ff-only",
 			Description: "Can fast-forward - safe to pull",
 			Priority:    48,
+			Evidence:    upstreamSyncEvidence,
 		},
 		{
 			ID:    "R020",
@@ -109,7 +172,39 @@ func R048(state model.RepoState) bool {
 
 // R049 - Squash recommended before merge
 func R049(state model.RepoState) bool {
-	return state.SquashRecommended
+	return state.SquashRecommended &&
+		RewriteIsSafe(state, state.CommitCountSincePush)
+}
+
+// R059 - Pending fixup commits ready to autosquash
+func R059(state model.RepoState) bool {
+	return len(state.PendingFixups) > 0 &&
+		!state.FixupTargetPublished &&
+		state.AutosquashBase != ""
+}
+
+// R060 - Fixup commits targeting published history
+func R060(state model.RepoState) bool {
+	return state.FixupTargetPublished
+}
+
+// fixupEvidence lists each pending fixup commit and its target
+func fixupEvidence(state model.RepoState) []string {
+	var evidence []string
+	for _, f := range state.PendingFixups {
+		target := "no matching commit"
+		if f.TargetHash != "" {
+			target = f.TargetHash[:7]
+			if f.TargetPublished {
+				target += " (published)"
+			}
+		}
+		evidence = append(evidence, fmt.Sprintf("%s %s -> %s", f.Hash[:7], f.Subject, target))
+	}
+	if state.AutosquashBase != "" {
+		evidence = append(evidence, "Base: "+state.AutosquashBase)
+	}
+	return evidence
 }
 
 // R050 - WIP commit on shared branch
@@ -125,7 +220,35 @@ func R051(state model.RepoState) bool {
 // R005 - Pull When Behind and Clean
 func R005(state model.RepoState) bool {
 	return state.Behind > 0 &&
-		!state.Dirty
+		!state.Dirty &&
+		!state.UpstreamRewritten
+}
+
+// R064 - Pull with autostash when local changes don't overlap
+func R064(state model.RepoState) bool {
+	return state.Behind > 0 &&
+		state.Dirty &&
+		len(state.IncomingPaths) > 0 &&
+		len(state.DirtyUpstreamOverlap) == 0 &&
+		!state.UpstreamRewritten
+}
+
+// R065 - Commit before pulling when local changes overlap
+func R065(state model.RepoState) bool {
+	return state.Behind > 0 &&
+		len(state.DirtyUpstreamOverlap) > 0 &&
+		!state.UpstreamRewritten
+}
+
+// dirtyPullEvidence compares the uncommitted changes with what the pull brings in
+func dirtyPullEvidence(state model.RepoState) []string {
+	if len(state.DirtyUpstreamOverlap) == 0 {
+		return []string{fmt.Sprintf("%d incoming file(s), none of them changed locally", len(state.IncomingPaths))}
+	}
+	return []string{
+		"Changed locally and upstream: " + strings.Join(state.DirtyUpstreamOverlap, ", "),
+		"Stashing would only move the conflict to git stash pop",
+	}
 }
 
 // R030 - Fast-Forward Pull
@@ -145,16 +268,16 @@ func R004(state model.RepoState) bool {
 // R020 - Soft Reset Local Commits
 func R020(state model.RepoState, cfg *config.Config) bool {
 	maxCommits := cfg.GetIntParam("R020", "max_commits", 3)
-	return !state.LastCommitPushed &&
-		state.CommitCountSincePush > 0 &&
-		state.CommitCountSincePush <= maxCommits
+	return state.CommitCountSincePush > 0 &&
+		state.CommitCountSincePush <= maxCommits &&
+		RewriteIsSafe(state, state.CommitCountSincePush)
 }
 
 // R022 - Too Many Commits to Reset
 func R022(state model.RepoState, cfg *config.Config) bool {
 	minCommits := cfg.GetIntParam("R022", "min_commits", 4)
-	return !state.LastCommitPushed &&
-		state.CommitCountSincePush >= minCommits
+	return state.CommitCountSincePush >= minCommits &&
+		RewriteIsSafe(state, state.CommitCountSincePush)
 }
 
 // R003 - Staged but Not Committed
@@ -167,3 +290,208 @@ func R002(state model.RepoState) bool {
 	return state.ModifiedFiles > 0 &&
 		state.StagedFiles == 0
 }
+
+// R072 - Branch name violates the naming policy
+func R072(state model.RepoState) bool {
+	return len(state.BranchNameViolations) > 0
+}
+
+// R072Evidence lists each violating branch with its suggested rename
+func R072Evidence(state model.RepoState) []string {
+	var evidence []string
+	for i, v := range state.BranchNameViolations {
+		if i == maxEvidencePaths {
+			evidence = append(evidence, fmt.Sprintf("... and %d more", len(state.BranchNameViolations)-i))
+			break
+		}
+		rename := "git branch -m " + v.Branch + " <name>"
+		if v.Current && v.Suggested != "" {
+			rename = "git branch -m " + v.Suggested
+		} else if v.Suggested != "" {
+			rename = "git branch -m " + v.Branch + " " + v.Suggested
+		}
+		line := fmt.Sprintf("%s -> %s", v.Branch, rename)
+		if v.Published {
+			line += " (already pushed: push the new name and delete the old one)"
+		}
+		evidence = append(evidence, line)
+	}
+	return append(evidence, "allowed: "+strings.Join(state.BranchNamePatterns, ", "))
+}
+
+// R073 - Wrong commit identity
+func R073(state model.RepoState) bool {
+	return state.IdentityProblem != "" || len(state.IdentityCommits) > 0
+}
+
+// R073Evidence explains the identity problem and the rewrite plan
+func R073Evidence(state model.RepoState) []string {
+	var evidence []string
+
+	if state.IdentityProblem != "" {
+		line := state.IdentityProblem
+		if state.IdentityEmail != "" {
+			line += fmt.Sprintf(" (commits get %s)", state.IdentityEmail)
+		}
+		if len(state.AllowedEmails) > 0 {
+			line += ", allowed: " + strings.Join(state.AllowedEmails, ", ")
+		}
+		evidence = append(evidence, line)
+		evidence = append(evidence, "set it first: git config user.email <you@example.com>")
+	}
+
+	for i, c := range state.IdentityCommits {
+		if i == maxEvidencePaths {
+			evidence = append(evidence, fmt.Sprintf("... and %d more", len(state.IdentityCommits)-i))
+			break
+		}
+		evidence = append(evidence, fmt.Sprintf("%s %s: %s %s", c.Hash[:7], c.Subject, c.Role, c.Email))
+	}
+
+	if len(state.IdentityCommits) > 0 {
+		evidence = append(evidence, IdentityRewritePlan(state)...)
+	}
+	return evidence
+}
+
+// IdentityRewritePlan returns the command that fixes the identity of the
+// flagged commits, and why it is or isn't safe
+func IdentityRewritePlan(state model.RepoState) []string {
+	oldest := state.IdentityCommits[len(state.IdentityCommits)-1]
+	return rewritePlan(state, oldest.Depth, IdentityRewriteCommand(state.IdentityCommits))
+}
+
+// IdentityRewriteCommand amends HEAD, or replays every commit since the
+// oldest flagged one and amends only those with a flagged email
+func IdentityRewriteCommand(commits []model.IdentityCommit) string {
+	oldest := commits[len(commits)-1]
+	if oldest.Depth == 1 {
+		return "git " + strings.Join(IdentityAmend(oldest), " ")
+	}
+	return fmt.Sprintf("git rebase --exec '%s' %s^", IdentityExec(commits), oldest.Hash[:7])
+}
+
+// IdentityAmend returns the git arguments that fix one commit. Amending
+// sets the committer anyway, only a wrong author needs --reset-author.
+func IdentityAmend(c model.IdentityCommit) []string {
+	if c.Role == "author" {
+		return []string{"commit", "--amend", "--no-edit", "--reset-author"}
+	}
+	return []string{"commit", "--amend", "--no-edit"}
+}
+
+// IdentityExec returns the rebase exec that amends a replayed commit when
+// its author or committer email is one of the flagged ones. The flags only
+// depend on the email, so the same email can't be right on another commit.
+func IdentityExec(commits []model.IdentityCommit) string {
+	var authors, committers []string
+	seen := make(map[string]bool)
+	for _, c := range commits {
+		key := c.Role + " " + c.Email
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if c.Role == "author" {
+			authors = append(authors, casePattern(c.Email))
+		} else {
+			committers = append(committers, casePattern(c.Email))
+		}
+	}
+
+	var script []string
+	if len(authors) > 0 {
+		script = append(script, fmt.Sprintf(`case "$(git log -1 --format=%%ae)" in %s) git commit --amend --no-edit --reset-author;; esac`,
+			strings.Join(authors, "|")))
+	}
+	if len(committers) > 0 {
+		script = append(script, fmt.Sprintf(`case "$(git log -1 --format=%%ce)" in %s) git commit --amend --no-edit;; esac`,
+			strings.Join(committers, "|")))
+	}
+	return strings.Join(script, " && ")
+}
+
+// casePattern quotes a string as a literal sh case pattern
+func casePattern(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	return `"` + r.Replace(s) + `"`
+}
+
+// signatureStatus describes the %G? codes R074 re-signs
+var signatureStatus = map[string]string{
+	"N": "unsigned",
+	"B": "bad signature",
+}
+
+// R074 - Unsigned or badly signed commits
+func R074(state model.RepoState) bool {
+	return state.SigningProblem != "" || len(state.SigningCommits) > 0 || len(state.SigningUnknown) > 0 ||
+		(state.SigningRequired && !state.SigningAutoSign)
+}
+
+// R074Evidence explains the signing problem and the re-signing plan
+func R074Evidence(state model.RepoState) []string {
+	var evidence []string
+
+	if state.SigningProblem != "" {
+		evidence = append(evidence, state.SigningProblem)
+	}
+	if state.SigningNote != "" {
+		evidence = append(evidence, state.SigningNote)
+	}
+	if state.SigningRequired && !state.SigningAutoSign {
+		evidence = append(evidence, "commit.gpgsign is not set, new commits are not signed: git config commit.gpgsign true")
+	}
+
+	for i, c := range state.SigningCommits {
+		if i == maxEvidencePaths {
+			evidence = append(evidence, fmt.Sprintf("... and %d more", len(state.SigningCommits)-i))
+			break
+		}
+		evidence = append(evidence, fmt.Sprintf("%s %s: %s", c.Hash[:7], c.Subject, signatureStatus[c.Status]))
+	}
+	for i, c := range state.SigningUnknown {
+		if i == maxEvidencePaths {
+			evidence = append(evidence, fmt.Sprintf("... and %d more", len(state.SigningUnknown)-i))
+			break
+		}
+		evidence = append(evidence, fmt.Sprintf("%s %s: cannot verify, import the signer's key", c.Hash[:7], c.Subject))
+	}
+
+	if len(state.SigningCommits) > 0 {
+		if state.SigningProblem != "" {
+			evidence = append(evidence, "fix the signing setup before re-signing")
+		}
+		evidence = append(evidence, SigningRewritePlan(state)...)
+	}
+	return evidence
+}
+
+// SigningRewritePlan returns the command that re-signs the flagged
+// commits, and why it is or isn't safe. Signed commits the rebase replays
+// lose their signature and get yours, which is noted for foreign keys.
+func SigningRewritePlan(state model.RepoState) []string {
+	oldest := state.SigningCommits[len(state.SigningCommits)-1]
+	plan := rewritePlan(state, oldest.Depth, SigningRewriteCommand(oldest))
+	if !strings.HasPrefix(plan[0], "fix: ") {
+		return plan
+	}
+	for _, c := range state.SigningUnknown {
+		if c.Depth < oldest.Depth {
+			plan = append(plan, fmt.Sprintf("%s is replayed by the rebase: its signature is replaced with yours", c.Hash[:7]))
+		}
+	}
+	return plan
+}
+
+// SigningExec is the rebase exec that re-signs each replayed commit
+const SigningExec = "git commit --amend --no-edit -S"
+
+// SigningRewriteCommand amends HEAD, or replays every commit since the
+// oldest flagged one with a signature
+func SigningRewriteCommand(oldest model.SigningCommit) string {
+	if oldest.Depth == 1 {
+		return "git commit --amend --no-edit -S"
+	}
+	return fmt.Sprintf("git rebase --exec '%s' %s^", SigningExec, oldest.Hash[:7])
+}
@@ -1,46 +1,116 @@
 package model
 
+import (
+	"fmt"
+	"time"
+)
+
 // RepoState represents the current state of a Git repository
 type RepoState struct {
 	Dirty                bool
 	StagedFiles          int
 	ModifiedFiles        int
 	UntrackedFiles       int
+	StagedPaths          []string
+	ModifiedPaths        []string
+	UntrackedPaths       []string
+	DeletedPaths         []string // deleted in the work tree, not staged
 	Ahead                int
 	Behind               int
 	HasStash             bool
 	OnDetachedHead       bool
 	LastCommitPushed     bool
 	CommitCountSincePush int
+	PublicationMap       []CommitPublication // newest first
+	UnpublishedCount     int                 // commits from HEAD before the first published one
 	OnProtectedBranch    bool
 	HasMergeCommits      bool
 
-	// Active operations (R9-R11)
+	// Active operations (R9-R11, R067-R069)
 	MergeInProgress      bool
 	RebaseInProgress     bool
 	CherryPickInProgress bool
+	RevertInProgress     bool
+	AmInProgress         bool
+	BisectInProgress     bool
+	SequencerInProgress  bool   // multi-commit cherry-pick or revert with commits left
+	SequencerOperation   string // "cherry-pick" or "revert"
+	SequencerRemaining   int    // commits left in sequencer/todo
 
 	// Branch health (R34-R36)
 	NoUpstream           bool
 	MergedBranches       []string
 	GoneBranches         []string
+	SquashMergedBranches []string // merged upstream by squash or rebase, need -D
 
 	// Dangerous operations (R037-R041)
 	ForcePushToShared       bool
 	RewrittenPublishedTags  bool
 	ResetOnProtectedBranch  bool
-	SubmoduleRewriteNoUpdate bool
 	AccidentalHistoryRewrite bool
+	RewrittenPublishedCommit string
 
 	// Repo integrity (R042-R046)
 	ConflictedFilesStaged    bool
 	ConflictedFiles          []string
+	ConflictMarkers          []string // path:first-last line of each staged conflict block
 	LargeBinariesWithoutLFS  bool
-	LargeBinaryFiles         []string
+	LargeBinaryFiles         []string // staged or unpushed, "(unpushed commit)" marks the latter
+	LFSTrackPatterns         []string // git lfs track patterns covering LargeBinaryFiles
+	LFSSmudgedFiles          []string // LFS-tracked paths committed with content instead of a pointer
+	LFSPointersWithoutLFS    []string // pointers committed while git-lfs is not installed
+	LFSInstalled             bool
 	LineEndingConflict       bool
-	SubmoduleDetachedHead    bool
-	SubmoduleName            string
+	LineEndingIssues         []LineEndingIssue
+	LineEndingAttributes     []string // suggested .gitattributes lines
+	LineEndingConfig         string   // core.autocrlf and core.eol as set
 	ShallowCloneHistoryOps   bool
+	UpstreamRewritten        bool
+	UpstreamForkPoint        string
+	UpstreamRewriteNote      string
+
+	// Branch naming (R072)
+	BranchNameViolations []BranchNameViolation // current branch first
+	BranchNamePatterns   []string              // allowed patterns from branch_naming
+
+	// Commit identity (R073)
+	IdentityEmail   string           // email the next commit gets
+	IdentityProblem string           // what is wrong with it, empty if nothing
+	IdentityRemote  string           // URL the identity policy was chosen by
+	AllowedEmails   []string         // email globs of the matching policy
+	IdentityCommits []IdentityCommit // unpushed commits with a wrong author or committer, newest first
+
+	// Commit signing (R074)
+	SigningRequired bool            // the config requires signing, not only commit.gpgsign
+	SigningAutoSign bool            // commit.gpgsign is set, new commits get signed
+	SigningProblem  string          // why signing fails, empty if nothing
+	SigningNote     string          // what git can't verify, R074 doesn't fire on it alone
+	SigningCommits  []SigningCommit // unpushed commits that are unsigned or badly signed, newest first
+	SigningUnknown  []SigningCommit // unpushed commits signed with a key missing here, never re-signed
+
+	// Submodules (R040, R045)
+	Submodules []Submodule // nested submodules included, in git submodule status order
+
+	// Lock files (R070)
+	StaleLocks       []LockFile // lock files no running git process holds
+	LockOwnerChecked bool       // /proc was scanned, otherwise the locks are only old
+
+	// Secrets (R071)
+	SecretFindings []SecretFinding // credentials in staged (or unpushed) changes
+
+	// Lost work (R066)
+	LostCommits []LostCommit // tips no branch, tag or stash reaches, newest first
+
+	// Sync prediction (R005, R006, R030-R033, R063-R065)
+	UpstreamMergePredicted  bool     // merging @{u} was simulated
+	UpstreamConflicts       []string // paths that would conflict merging @{u}
+	DefaultBranch           string
+	DefaultMergePredicted   bool
+	DefaultBranchConflicts  []string // paths that would conflict merging the default branch
+	RebaseConflictCommits   int      // local commits touching conflicting paths
+	PreferMergeForConflicts bool
+	IncomingPaths           []string // paths changed by HEAD..@{u}, set when behind with a dirty tree
+	DirtyUpstreamOverlap    []string // local changes that incoming commits also touch
 
 	// Workflow hygiene (R047-R051)
 	WorkOnMainNotFeature     bool
@@ -51,6 +121,9 @@ type RepoState struct {
 	WIPCommitOnShared        bool
 	WIPCommitMessage         string
 	RebaseInsteadOfMerge     bool
+	PendingFixups            []FixupCommit
+	AutosquashBase           string
+	FixupTargetPublished     bool
 
 	// Mild suggestions (R052-R055)
 	PoorCommitMessage        bool
@@ -69,6 +142,163 @@ type RepoState struct {
 	OnDetachedHeadClean      bool
 }
 
+// CommitPublication records which refs contain a local commit
+type CommitPublication struct {
+	Hash           string
+	Subject        string
+	Merge          bool // more than one parent: a rebase would flatten it
+	RemoteBranches []string
+	LocalBranches  []string // other than the current branch
+	Tags           []string
+	Stashes        []string
+}
+
+// Published reports whether a remote-tracking branch contains the commit
+func (c CommitPublication) Published() bool {
+	return len(c.RemoteBranches) > 0
+}
+
+// SharedLocally reports whether other local refs would keep the old commit alive
+func (c CommitPublication) SharedLocally() bool {
+	return len(c.LocalBranches) > 0 || len(c.Tags) > 0 || len(c.Stashes) > 0
+}
+
+// LostCommit is the tip of commits that nothing references anymore
+type LostCommit struct {
+	Hash      string
+	Subject   string
+	Timestamp int64  // committer date, unix seconds
+	Commits   int    // lost commits reachable from this tip
+	Source    string // "left by checkout", "left by reset", "dropped stash" or "unreachable"
+}
+
+// Age describes how long ago the lost commit was made
+func (l LostCommit) Age() string {
+	return FormatAge(time.Unix(l.Timestamp, 0))
+}
+
+// SecretFinding is a credential found in an added line or file
+type SecretFinding struct {
+	Path      string
+	Line      int    // 0 for findings by file name
+	Kind      string // e.g. "private key", "GitHub token", "secret file"
+	Match     string // the matched text, never print it unmasked
+	Committed bool   // in an unpushed commit rather than the index
+}
+
+// Location returns path:line, or the path alone for file name findings
+func (f SecretFinding) Location() string {
+	if f.Line == 0 {
+		return f.Path
+	}
+	return fmt.Sprintf("%s:%d", f.Path, f.Line)
+}
+
+// Masked returns the start of the match with the rest hidden
+func (f SecretFinding) Masked() string {
+	if f.Kind == "private key" || f.Kind == "secret file" {
+		return f.Match
+	}
+	if len(f.Match) <= 8 {
+		return "****"
+	}
+	return f.Match[:4] + "****"
+}
+
+// LineEndingIssue is a file whose line endings fight the attributes or each other
+type LineEndingIssue struct {
+	Path    string
+	Problem string
+}
+
+// BranchNameViolation is a branch whose name the branch_naming policy rejects
+type BranchNameViolation struct {
+	Branch    string
+	Suggested string // derived from the old name, empty if nothing fits
+	Current   bool
+	Published bool // has an upstream or a remote branch of the same name
+}
+
+// IdentityCommit is an unpushed commit whose author or committer email is wrong
+type IdentityCommit struct {
+	Hash    string
+	Subject string
+	Role    string // "author" or "committer"
+	Email   string
+	Depth   int // 1 for HEAD, counting first parents, so the commit is HEAD~(Depth-1)
+}
+
+// SigningCommit is an unpushed commit without a good signature
+type SigningCommit struct {
+	Hash    string
+	Subject string
+	Status  string // %G? of the commit: "N" or "B", "E" in SigningUnknown
+	Depth   int    // 1 for HEAD, counting first parents, so the commit is HEAD~(Depth-1)
+}
+
+// Submodule states as reported by the git submodule status prefix
+const (
+	SubmoduleInSync        = "in sync"       // " "
+	SubmoduleUninitialized = "uninitialized" // "-"
+	SubmoduleOutOfSync     = "out of sync"   // "+": checked-out commit differs from the index
+	SubmoduleConflict      = "conflict"      // "U"
+)
+
+// Submodule is the health of one submodule
+type Submodule struct {
+	Path           string // relative to the top of the superproject
+	State          string
+	Commit         string // checked out, or recorded when uninitialized
+	Recorded       string // commit the parent repository's index records
+	Behind         bool   // checked-out commit is older than the recorded one: update never ran
+	Ahead          bool   // checked-out commit is newer than the recorded one: not added yet
+	Dirty          int    // changed or untracked files in the submodule
+	DetachedWork   bool   // detached HEAD with commits no branch contains
+	StagedUnpushed string // staged pointer to a commit no remote branch of the submodule has
+	URL            string // in .gitmodules
+	ConfiguredURL  string // in .git/config, empty before init
+}
+
+// URLDrift reports whether .git/config points somewhere else than .gitmodules
+func (s Submodule) URLDrift() bool {
+	return s.URL != "" && s.ConfiguredURL != "" && s.URL != s.ConfiguredURL
+}
+
+// LockFile is a lock git left behind in the git directory
+type LockFile struct {
+	Path      string // absolute path
+	Name      string // path relative to the git directory, e.g. refs/heads/main.lock
+	Timestamp int64  // modification time, unix seconds
+}
+
+// Age describes how long ago the lock was taken
+func (l LockFile) Age() string {
+	return FormatAge(time.Unix(l.Timestamp, 0))
+}
+
+// FormatAge describes how long ago t was, in minutes, hours or days
+func FormatAge(t time.Time) string {
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%d minutes ago", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%d hours ago", int(age.Hours()))
+	}
+	return fmt.Sprintf("%d days ago", int(age.Hours()/24))
+}
+
+// FixupCommit is a fixup!/squash!/amend! commit waiting for autosquash
+type FixupCommit struct {
+	Hash            string
+	Kind            string // "fixup", "squash" or "amend"
+	Subject         string
+	TargetHash      string // empty if no matching commit was found
+	TargetPublished bool
+}
+
 // Advice represents a single piece of actionable advice
 type Advice struct {
 	RuleID      string
@@ -77,6 +307,7 @@ type Advice struct {
 	Priority    int
 	Suppressed  bool
 	Reason      string
+	Evidence    []string
 }
 
 // ByPriority implements sort.Interface for []Advice based on Priority field